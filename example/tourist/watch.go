@@ -0,0 +1,23 @@
+package tourist
+
+import (
+	"context"
+	"fmt"
+	"github.com/piaofutong/odas-sdk/odas"
+	"github.com/piaofutong/odas-sdk/odas/tourist"
+	"time"
+)
+
+func watch() {
+	iam := odas.NewIAM("your_access_id", "your_access_key")
+	token := "your_token"
+	ctx, cancel := context.WithTimeout(context.Background(), time.Hour)
+	defer cancel()
+	for event := range tourist.Watch(ctx, iam, "42,43", 5*time.Second, odas.WithToken(token)) {
+		if event.Err != nil {
+			fmt.Println(event.Err)
+			continue
+		}
+		fmt.Println(event.Time, event.In, event.Out, event.Hold)
+	}
+}
@@ -0,0 +1,150 @@
+package odas
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type Granularity int
+
+const (
+	GranularityHour Granularity = iota + 1
+	GranularityDay
+	GranularityMonth
+)
+
+func (g Granularity) String() string {
+	switch g {
+	case GranularityHour:
+		return "hour"
+	case GranularityDay:
+		return "day"
+	case GranularityMonth:
+		return "month"
+	}
+	return fmt.Sprintf("Granularity(%d)", int(g))
+}
+
+// Bucket 时间桶，Time 为桶起始时刻
+type Bucket struct {
+	Time        time.Time
+	Granularity Granularity
+}
+
+func (b Bucket) String() string {
+	switch b.Granularity {
+	case GranularityHour:
+		return b.Time.Format("2006-01-02 15:00")
+	case GranularityMonth:
+		return b.Time.Format("2006-01")
+	}
+	return b.Time.Format(dateLayout)
+}
+
+// Next 下一个时间桶
+func (b Bucket) Next() Bucket {
+	switch b.Granularity {
+	case GranularityHour:
+		return Bucket{Time: b.Time.Add(time.Hour), Granularity: b.Granularity}
+	case GranularityMonth:
+		return Bucket{Time: b.Time.AddDate(0, 1, 0), Granularity: b.Granularity}
+	}
+	return Bucket{Time: b.Time.AddDate(0, 0, 1), Granularity: b.Granularity}
+}
+
+// Bucketed 带时间键的序列项，day 用于补全只有时分的时间键
+type Bucketed interface {
+	Bucket(day time.Time) (Bucket, error)
+}
+
+func NewBucket(t time.Time, granularity Granularity) Bucket {
+	t = t.In(Shanghai)
+	switch granularity {
+	case GranularityHour:
+		t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), 0, 0, 0, Shanghai)
+	case GranularityMonth:
+		t = time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, Shanghai)
+	default:
+		t = time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, Shanghai)
+	}
+	return Bucket{Time: t, Granularity: granularity}
+}
+
+var bucketLayouts = []struct {
+	layout      string
+	granularity Granularity
+}{
+	{"2006-01-02 15:04:05", GranularityHour},
+	{"2006-01-02 15:04", GranularityHour},
+	{"2006-01-02 15", GranularityHour},
+	{"2006-01-02T15:04:05Z07:00", GranularityHour},
+	{"2006-01-02", GranularityDay},
+	{"2006/01/02", GranularityDay},
+	{"20060102", GranularityDay},
+	{"2006-01", GranularityMonth},
+	{"2006/01", GranularityMonth},
+	{"200601", GranularityMonth},
+}
+
+var clockRange = regexp.MustCompile(`^(\d{1,2})(?::\d{2}){0,2}(?:\s*[-~]\s*\d{1,2}(?::\d{2}){0,2})?$`)
+
+// ParseBucket 解析字符串时间键，如 "2024-11-01"、"2024-11-01 10:00"、"2024-11"、"10:00"、"10:00-11:00"；
+// 只有时分的键落在 day 当天
+func ParseBucket(v string, day time.Time) (Bucket, error) {
+	v = strings.TrimSpace(v)
+	if v == "" {
+		return Bucket{}, fmt.Errorf("empty time key")
+	}
+	if m := clockRange.FindStringSubmatch(v); m != nil {
+		hour, _ := strconv.Atoi(m[1])
+		return hourOf(day, hour, v)
+	}
+	if i := strings.Index(v, "~"); i > 0 {
+		v = strings.TrimSpace(v[:i])
+	}
+	for _, l := range bucketLayouts {
+		if t, err := time.ParseInLocation(l.layout, v, Shanghai); err == nil {
+			return NewBucket(t, l.granularity), nil
+		}
+	}
+	return Bucket{}, fmt.Errorf("unrecognized time key %q", v)
+}
+
+// ParseBucketInt 解析整数时间键：yyyymmdd、yyyymmddHH、yyyymm、0-23 小时、秒或毫秒时间戳
+func ParseBucketInt(v int, day time.Time) (Bucket, error) {
+	if v >= 0 && v < 24 {
+		return hourOf(day, v, strconv.Itoa(v))
+	}
+	s := strconv.Itoa(v)
+	switch len(s) {
+	case 6:
+		return ParseBucket(s, day)
+	case 8:
+		return ParseBucket(s, day)
+	case 10:
+		if t, err := time.ParseInLocation("2006010215", s, Shanghai); err == nil && t.Year() >= 1990 && t.Year() < 2100 {
+			return NewBucket(t, GranularityHour), nil
+		}
+		return NewBucket(time.Unix(int64(v), 0), GranularityHour), nil
+	case 13:
+		return NewBucket(time.UnixMilli(int64(v)), GranularityHour), nil
+	}
+	return Bucket{}, fmt.Errorf("unrecognized time key %d", v)
+}
+
+func hourOf(day time.Time, hour int, raw string) (Bucket, error) {
+	if day.IsZero() {
+		return Bucket{}, fmt.Errorf("time key %q has no date", raw)
+	}
+	if hour > 23 {
+		return Bucket{}, fmt.Errorf("invalid hour in time key %q", raw)
+	}
+	d := day.In(Shanghai)
+	return Bucket{
+		Time:        time.Date(d.Year(), d.Month(), d.Day(), hour, 0, 0, 0, Shanghai),
+		Granularity: GranularityHour,
+	}, nil
+}
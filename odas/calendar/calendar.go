@@ -0,0 +1,309 @@
+package calendar
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/piaofutong/odas-sdk/odas"
+)
+
+const dateLayout = "2006-01-02"
+
+type Holiday string
+
+const (
+	NewYear        Holiday = "newYear"        // 元旦
+	SpringFestival Holiday = "springFestival" // 春节
+	Qingming       Holiday = "qingming"       // 清明节
+	LaborDay       Holiday = "laborDay"       // 劳动节
+	DragonBoat     Holiday = "dragonBoat"     // 端午节
+	MidAutumn      Holiday = "midAutumn"      // 中秋节
+	NationalDay    Holiday = "nationalDay"    // 国庆节
+	Summer         Holiday = "summer"         // 暑期旺季，7 月 1 日至 8 月 31 日
+)
+
+type DayType int
+
+const (
+	Workday DayType = iota + 1
+	Weekend
+	HolidayDay
+)
+
+func (d DayType) String() string {
+	switch d {
+	case Workday:
+		return "workday"
+	case Weekend:
+		return "weekend"
+	case HolidayDay:
+		return "holiday"
+	}
+	return fmt.Sprintf("DayType(%d)", int(d))
+}
+
+// Period 某年某个节假日的放假区间(含首尾)及调休上班日
+type Period struct {
+	Name     Holiday
+	Year     int
+	Start    time.Time
+	End      time.Time
+	Workdays []time.Time
+}
+
+// Days 放假天数
+func (p *Period) Days() int {
+	return int(p.End.Sub(p.Start).Hours()/24) + 1
+}
+
+type periodJSON struct {
+	Name     Holiday  `json:"name"`
+	Year     int      `json:"year"`
+	Start    string   `json:"start"`
+	End      string   `json:"end"`
+	Workdays []string `json:"workdays,omitempty"`
+}
+
+type periodKey struct {
+	name Holiday
+	year int
+}
+
+// Calendar 节假日日历，可通过 Register、Load 补充或覆盖某年的安排。
+// 同一日期可属于多个节假日(如中秋与国庆合并放假)，按日期索引登记全部所属节假日
+type Calendar struct {
+	mutex    sync.RWMutex
+	periods  map[periodKey]*Period
+	holidays map[string][]Holiday
+	workdays map[string][]Holiday
+}
+
+func New() *Calendar {
+	return &Calendar{
+		periods:  make(map[periodKey]*Period),
+		holidays: make(map[string][]Holiday),
+		workdays: make(map[string][]Holiday),
+	}
+}
+
+var (
+	defaultOnce     sync.Once
+	defaultCalendar *Calendar
+)
+
+// Default 内置法定节假日表的共享日历
+func Default() *Calendar {
+	defaultOnce.Do(func() {
+		defaultCalendar = New()
+		for _, p := range builtin {
+			period, err := p.period()
+			if err != nil {
+				panic(err)
+			}
+			defaultCalendar.Register(period)
+		}
+	})
+	return defaultCalendar
+}
+
+func (p periodJSON) period() (*Period, error) {
+	start, err := time.ParseInLocation(dateLayout, p.Start, odas.Shanghai)
+	if err != nil {
+		return nil, err
+	}
+	end, err := time.ParseInLocation(dateLayout, p.End, odas.Shanghai)
+	if err != nil {
+		return nil, err
+	}
+	if end.Before(start) {
+		return nil, fmt.Errorf("%s %d: end before start", p.Name, p.Year)
+	}
+	period := &Period{Name: p.Name, Year: p.Year, Start: start, End: end}
+	for _, w := range p.Workdays {
+		d, err := time.ParseInLocation(dateLayout, w, odas.Shanghai)
+		if err != nil {
+			return nil, err
+		}
+		period.Workdays = append(period.Workdays, d)
+	}
+	return period, nil
+}
+
+// Register 登记或覆盖某年的节假日安排
+func (c *Calendar) Register(p *Period) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	key := periodKey{p.Name, p.Year}
+	if old, ok := c.periods[key]; ok {
+		c.unindex(old)
+	}
+	c.periods[key] = p
+	for d := p.Start; !d.After(p.End); d = d.AddDate(0, 0, 1) {
+		index(c.holidays, d.Format(dateLayout), p.Name)
+	}
+	for _, w := range p.Workdays {
+		index(c.workdays, w.Format(dateLayout), p.Name)
+	}
+}
+
+// unindex 只移除 p 自身的索引，与其他节假日共用的日期保留其他节假日
+func (c *Calendar) unindex(p *Period) {
+	for d := p.Start; !d.After(p.End); d = d.AddDate(0, 0, 1) {
+		unindex(c.holidays, d.Format(dateLayout), p.Name)
+	}
+	for _, w := range p.Workdays {
+		unindex(c.workdays, w.Format(dateLayout), p.Name)
+	}
+}
+
+func index(m map[string][]Holiday, date string, name Holiday) {
+	for _, n := range m[date] {
+		if n == name {
+			return
+		}
+	}
+	m[date] = append(m[date], name)
+}
+
+func unindex(m map[string][]Holiday, date string, name Holiday) {
+	names := m[date]
+	for i, n := range names {
+		if n == name {
+			names = append(names[:i:i], names[i+1:]...)
+			break
+		}
+	}
+	if len(names) == 0 {
+		delete(m, date)
+		return
+	}
+	m[date] = names
+}
+
+// Load 从 JSON 数组读取节假日安排，格式同内置表：
+// [{"name":"nationalDay","year":2027,"start":"2027-10-01","end":"2027-10-07","workdays":["2027-09-26"]}]
+func (c *Calendar) Load(r io.Reader) error {
+	var list []periodJSON
+	if err := json.NewDecoder(r).Decode(&list); err != nil {
+		return err
+	}
+	for _, p := range list {
+		period, err := p.period()
+		if err != nil {
+			return err
+		}
+		c.Register(period)
+	}
+	return nil
+}
+
+// Lookup 查询某年的节假日区间，Summer 按每年 7、8 月计算
+func (c *Calendar) Lookup(name Holiday, year int) (*Period, error) {
+	if name == Summer {
+		return &Period{
+			Name:  Summer,
+			Year:  year,
+			Start: time.Date(year, time.July, 1, 0, 0, 0, 0, odas.Shanghai),
+			End:   time.Date(year, time.August, 31, 0, 0, 0, 0, odas.Shanghai),
+		}, nil
+	}
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	if p, ok := c.periods[periodKey{name, year}]; ok {
+		return p, nil
+	}
+	return nil, fmt.Errorf("no %s schedule for %d", name, year)
+}
+
+// Periods 返回某年已登记的全部节假日，按开始日期排序
+func (c *Calendar) Periods(year int) []*Period {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	var out []*Period
+	for key, p := range c.periods {
+		if key.year == year {
+			out = append(out, p)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if !out[i].Start.Equal(out[j].Start) {
+			return out[i].Start.Before(out[j].Start)
+		}
+		return out[i].Name < out[j].Name
+	})
+	return out
+}
+
+// DayType 判断日期类型：法定节假日优先，其次调休上班日，最后按周末区分
+func (c *Calendar) DayType(t time.Time) DayType {
+	key := t.In(odas.Shanghai).Format(dateLayout)
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	if len(c.holidays[key]) > 0 {
+		return HolidayDay
+	}
+	if len(c.workdays[key]) > 0 {
+		return Workday
+	}
+	if wd := t.In(odas.Shanghai).Weekday(); wd == time.Saturday || wd == time.Sunday {
+		return Weekend
+	}
+	return Workday
+}
+
+// IsHoliday 是否法定节假日，可直接用于 forecast.WithHolidays
+func (c *Calendar) IsHoliday(t time.Time) bool {
+	return c.DayType(t) == HolidayDay
+}
+
+// HolidayOf 返回日期所属的节假日名称，属于多个节假日时返回最先登记的一个，全部名称见 HolidaysOf
+func (c *Calendar) HolidayOf(t time.Time) (Holiday, bool) {
+	names := c.HolidaysOf(t)
+	if len(names) == 0 {
+		return "", false
+	}
+	return names[0], true
+}
+
+// HolidaysOf 返回日期所属的全部节假日名称，按登记顺序
+func (c *Calendar) HolidaysOf(t time.Time) []Holiday {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return append([]Holiday(nil), c.holidays[t.In(odas.Shanghai).Format(dateLayout)]...)
+}
+
+// Tag 判断序列项所在时间桶的日期类型
+func (c *Calendar) Tag(item odas.Bucketed, day time.Time) (DayType, error) {
+	b, err := item.Bucket(day)
+	if err != nil {
+		return 0, err
+	}
+	return c.DayType(b.Time), nil
+}
+
+// Req 以 base 为模板，生成某年节假日区间的查询参数
+func (c *Calendar) Req(name Holiday, year int, base odas.Req) (*odas.Req, error) {
+	p, err := c.Lookup(name, year)
+	if err != nil {
+		return nil, err
+	}
+	base.Start = p.Start.Format(dateLayout)
+	base.End = p.End.Format(dateLayout)
+	return &base, nil
+}
+
+// CompareReq 生成与上一年同一节假日对比的参数，如今年国庆对比去年国庆
+func (c *Calendar) CompareReq(name Holiday, year int) (*odas.DateRangeCompareReq, error) {
+	p, err := c.Lookup(name, year-1)
+	if err != nil {
+		return nil, err
+	}
+	return &odas.DateRangeCompareReq{
+		CompareStart: p.Start.Format(dateLayout),
+		CompareEnd:   p.End.Format(dateLayout),
+	}, nil
+}
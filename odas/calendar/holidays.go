@@ -0,0 +1,36 @@
+package calendar
+
+// builtin 国务院办公厅公布的法定节假日安排，放假区间含首尾，workdays 为调休上班日
+var builtin = []periodJSON{
+	{NewYear, 2023, "2022-12-31", "2023-01-02", nil},
+	{SpringFestival, 2023, "2023-01-21", "2023-01-27", []string{"2023-01-28", "2023-01-29"}},
+	{Qingming, 2023, "2023-04-05", "2023-04-05", nil},
+	{LaborDay, 2023, "2023-04-29", "2023-05-03", []string{"2023-04-23", "2023-05-06"}},
+	{DragonBoat, 2023, "2023-06-22", "2023-06-24", []string{"2023-06-25"}},
+	{MidAutumn, 2023, "2023-09-29", "2023-10-06", []string{"2023-10-07", "2023-10-08"}},
+	{NationalDay, 2023, "2023-09-29", "2023-10-06", []string{"2023-10-07", "2023-10-08"}},
+
+	{NewYear, 2024, "2023-12-30", "2024-01-01", nil},
+	{SpringFestival, 2024, "2024-02-10", "2024-02-17", []string{"2024-02-04", "2024-02-18"}},
+	{Qingming, 2024, "2024-04-04", "2024-04-06", []string{"2024-04-07"}},
+	{LaborDay, 2024, "2024-05-01", "2024-05-05", []string{"2024-04-28", "2024-05-11"}},
+	{DragonBoat, 2024, "2024-06-08", "2024-06-10", nil},
+	{MidAutumn, 2024, "2024-09-15", "2024-09-17", []string{"2024-09-14"}},
+	{NationalDay, 2024, "2024-10-01", "2024-10-07", []string{"2024-09-29", "2024-10-12"}},
+
+	{NewYear, 2025, "2025-01-01", "2025-01-01", nil},
+	{SpringFestival, 2025, "2025-01-28", "2025-02-04", []string{"2025-01-26", "2025-02-08"}},
+	{Qingming, 2025, "2025-04-04", "2025-04-06", nil},
+	{LaborDay, 2025, "2025-05-01", "2025-05-05", []string{"2025-04-27"}},
+	{DragonBoat, 2025, "2025-05-31", "2025-06-02", nil},
+	{MidAutumn, 2025, "2025-10-01", "2025-10-08", []string{"2025-09-28", "2025-10-11"}},
+	{NationalDay, 2025, "2025-10-01", "2025-10-08", []string{"2025-09-28", "2025-10-11"}},
+
+	{NewYear, 2026, "2026-01-01", "2026-01-03", []string{"2026-01-04"}},
+	{SpringFestival, 2026, "2026-02-15", "2026-02-23", []string{"2026-02-14", "2026-02-28"}},
+	{Qingming, 2026, "2026-04-04", "2026-04-06", nil},
+	{LaborDay, 2026, "2026-05-01", "2026-05-05", []string{"2026-05-09"}},
+	{DragonBoat, 2026, "2026-06-19", "2026-06-21", nil},
+	{MidAutumn, 2026, "2026-09-25", "2026-09-27", nil},
+	{NationalDay, 2026, "2026-10-01", "2026-10-07", []string{"2026-09-20", "2026-10-10"}},
+}
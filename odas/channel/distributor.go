@@ -0,0 +1,445 @@
+package channel
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/piaofutong/odas-sdk/odas"
+)
+
+const dateLayout = "2006-01-02"
+
+func (s StatDistributorSummaryResponse) Bucket(day time.Time) (odas.Bucket, error) {
+	return odas.ParseBucket(s.Date, day)
+}
+
+// DistributorPoint 分销商某一天的数据
+type DistributorPoint struct {
+	Date string `json:"date"`
+	OrderChannelTotal
+}
+
+// DistributorSeries 分销商按日的时间序列，Points 覆盖整个统计区间，无数据的日期为 0
+type DistributorSeries struct {
+	DistributorId   int                 `json:"distributorId"`
+	DistributorName string              `json:"distributorName"`
+	Points          []*DistributorPoint `json:"points"`
+}
+
+// Total 区间合计
+func (s *DistributorSeries) Total() OrderChannelTotal {
+	var total OrderChannelTotal
+	for _, p := range s.Points {
+		total.OrderCount += p.OrderCount
+		total.TicketCount += p.TicketCount
+		total.Amount += p.Amount
+	}
+	return total
+}
+
+// PivotDistributors 将按分销商、按日的明细转为各分销商的时间序列。
+// start、end 为空时取数据中的最早、最晚日期；指定时区间外的明细被忽略，start 晚于 end 时返回错误；
+// 同一分销商同一天的多条数据合并
+func PivotDistributors(list []*StatDistributorSummaryResponse, start, end string) ([]*DistributorSeries, error) {
+	var from, to time.Time
+	var err error
+	if start != "" {
+		if from, err = time.ParseInLocation(dateLayout, start, odas.Shanghai); err != nil {
+			return nil, fmt.Errorf("invalid start %q", start)
+		}
+	}
+	if end != "" {
+		if to, err = time.ParseInLocation(dateLayout, end, odas.Shanghai); err != nil {
+			return nil, fmt.Errorf("invalid end %q", end)
+		}
+	}
+	if !from.IsZero() && !to.IsZero() && from.After(to) {
+		return nil, fmt.Errorf("start %s is after end %s", start, end)
+	}
+
+	type key struct {
+		id   int
+		date string
+	}
+	cells := make(map[key]*OrderChannelTotal)
+	names := make(map[int]string)
+	var ids []int
+	var first, last time.Time
+	for _, item := range list {
+		b, err := item.Bucket(time.Now().In(odas.Shanghai))
+		if err != nil {
+			return nil, fmt.Errorf("distributor %d: %w", item.DistributorID, err)
+		}
+		day := b.Time
+		if (!from.IsZero() && day.Before(from)) || (!to.IsZero() && day.After(to)) {
+			continue
+		}
+		if first.IsZero() || day.Before(first) {
+			first = day
+		}
+		if day.After(last) {
+			last = day
+		}
+		if _, ok := names[item.DistributorID]; !ok {
+			ids = append(ids, item.DistributorID)
+		}
+		if item.DistributorName != "" || names[item.DistributorID] == "" {
+			names[item.DistributorID] = item.DistributorName
+		}
+		k := key{item.DistributorID, day.Format(dateLayout)}
+		c, ok := cells[k]
+		if !ok {
+			c = &OrderChannelTotal{}
+			cells[k] = c
+		}
+		c.OrderCount += item.OrderCount
+		c.TicketCount += item.TicketCount
+		c.Amount += item.Amount
+	}
+	if !from.IsZero() {
+		first = from
+	}
+	if !to.IsZero() {
+		last = to
+	}
+	if first.IsZero() && !last.IsZero() {
+		first = last
+	}
+	if last.IsZero() && !first.IsZero() {
+		last = first
+	}
+	var dates []string
+	if !first.IsZero() {
+		for d := first; !d.After(last); d = d.AddDate(0, 0, 1) {
+			dates = append(dates, d.Format(dateLayout))
+		}
+	}
+
+	series := make([]*DistributorSeries, 0, len(ids))
+	for _, id := range ids {
+		s := &DistributorSeries{DistributorId: id, DistributorName: names[id], Points: make([]*DistributorPoint, len(dates))}
+		for i, date := range dates {
+			s.Points[i] = &DistributorPoint{Date: date}
+			if c, ok := cells[key{id, date}]; ok {
+				s.Points[i].OrderChannelTotal = *c
+			}
+		}
+		series = append(series, s)
+	}
+	return series, nil
+}
+
+// DropOff 分销商销售额骤降：最近 Window 天日均低于此前日均的 1-Threshold
+type DropOff struct {
+	Since    string  `json:"since"`
+	Baseline float64 `json:"baseline"`
+	Recent   float64 `json:"recent"`
+	Change   float64 `json:"change"`
+}
+
+// DistributorScore 分销商评分卡。
+// AvgPrice 为平均票价(销售额/票数)；Share 为销售额占全部分销商的比例；
+// Growth 为销售额增长率，有上期数据时按上期对比，否则按区间后半段对比前半段，无法计算时为空；
+// Consistency 为日销售额稳定度 1-变异系数，限制在 0~1 之间，越大越平稳
+type DistributorScore struct {
+	Rank            int      `json:"rank"`
+	DistributorId   int      `json:"distributorId"`
+	DistributorName string   `json:"distributorName"`
+	OrderCount      int      `json:"orderCount"`
+	TicketCount     int      `json:"ticketCount"`
+	Amount          int      `json:"amount"`
+	AvgPrice        float64  `json:"avgPrice"`
+	Share           float64  `json:"share"`
+	Growth          *float64 `json:"growth"`
+	Consistency     float64  `json:"consistency"`
+	ActiveDays      int      `json:"activeDays"`
+	DropOff         *DropOff `json:"dropOff,omitempty"`
+
+	series *DistributorSeries
+}
+
+// Series 评分所依据的时间序列
+func (s *DistributorScore) Series() *DistributorSeries {
+	return s.series
+}
+
+// RankBy 排名依据
+type RankBy int
+
+const (
+	RankByAmount RankBy = iota
+	RankByTickets
+	RankByOrders
+	RankByGrowth
+	RankByConsistency
+	RankByAvgPrice
+)
+
+type ScorecardOptions struct {
+	Previous      []*StatDistributorSummaryResponse
+	PreviousStart string
+	PreviousEnd   string
+	Start         string
+	End           string
+	RankBy        RankBy
+	DropWindow    int
+	DropThreshold float64
+	DropMinAmount float64
+}
+
+type ScorecardOption func(options *ScorecardOptions)
+
+// WithScorecardPrevious 上期明细，用于计算环比/同比增长
+func WithScorecardPrevious(previous []*StatDistributorSummaryResponse) ScorecardOption {
+	return func(options *ScorecardOptions) {
+		options.Previous = previous
+	}
+}
+
+// WithScorecardPreviousDateRange 上期统计区间，区间外的上期明细被忽略。
+// 指定了 WithScorecardDateRange 且有上期明细时必须同时指定，否则两期窗口不一致
+func WithScorecardPreviousDateRange(start, end string) ScorecardOption {
+	return func(options *ScorecardOptions) {
+		options.PreviousStart = start
+		options.PreviousEnd = end
+	}
+}
+
+// WithScorecardDateRange 统计区间，默认取数据中的最早、最晚日期
+func WithScorecardDateRange(start, end string) ScorecardOption {
+	return func(options *ScorecardOptions) {
+		options.Start = start
+		options.End = end
+	}
+}
+
+func WithScorecardRankBy(by RankBy) ScorecardOption {
+	return func(options *ScorecardOptions) {
+		options.RankBy = by
+	}
+}
+
+// WithScorecardDropOff 骤降判定：最近 window 天日均比此前日均下降超过 threshold，且此前日均不低于 minAmount。
+// 默认 3 天、下降 50%、不限金额
+func WithScorecardDropOff(window int, threshold, minAmount float64) ScorecardOption {
+	return func(options *ScorecardOptions) {
+		options.DropWindow = window
+		options.DropThreshold = threshold
+		options.DropMinAmount = minAmount
+	}
+}
+
+// DistributorReport 分销商评分与排名
+type DistributorReport struct {
+	Start  string              `json:"start"`
+	End    string              `json:"end"`
+	Total  OrderChannelTotal   `json:"total"`
+	Scores []*DistributorScore `json:"scores"`
+}
+
+// Scorecards 计算各分销商的评分卡并排名
+func Scorecards(list []*StatDistributorSummaryResponse, opt ...ScorecardOption) (*DistributorReport, error) {
+	options := &ScorecardOptions{DropWindow: 3, DropThreshold: 0.5}
+	for _, p := range opt {
+		p(options)
+	}
+	series, err := PivotDistributors(list, options.Start, options.End)
+	if err != nil {
+		return nil, err
+	}
+	var previous map[int]int
+	if options.Previous != nil {
+		if (options.Start != "" || options.End != "") && options.PreviousStart == "" && options.PreviousEnd == "" {
+			return nil, errors.New("previous date range is required when date range is set")
+		}
+		prevSeries, err := PivotDistributors(options.Previous, options.PreviousStart, options.PreviousEnd)
+		if err != nil {
+			return nil, fmt.Errorf("previous: %w", err)
+		}
+		previous = make(map[int]int)
+		for _, s := range prevSeries {
+			previous[s.DistributorId] = s.Total().Amount
+		}
+	}
+
+	report := &DistributorReport{Start: options.Start, End: options.End}
+	for _, s := range series {
+		total := s.Total()
+		report.Total.OrderCount += total.OrderCount
+		report.Total.TicketCount += total.TicketCount
+		report.Total.Amount += total.Amount
+		score := &DistributorScore{
+			DistributorId:   s.DistributorId,
+			DistributorName: s.DistributorName,
+			OrderCount:      total.OrderCount,
+			TicketCount:     total.TicketCount,
+			Amount:          total.Amount,
+			Consistency:     consistency(s.Points),
+			DropOff:         dropOff(s.Points, options),
+			series:          s,
+		}
+		if total.TicketCount > 0 {
+			score.AvgPrice = float64(total.Amount) / float64(total.TicketCount)
+		}
+		for _, p := range s.Points {
+			if p.Amount > 0 || p.TicketCount > 0 {
+				score.ActiveDays++
+			}
+		}
+		if previous != nil {
+			score.Growth = growth(float64(previous[s.DistributorId]), float64(total.Amount))
+		} else {
+			half := len(s.Points) / 2
+			score.Growth = growth(sumAmount(s.Points[:half]), sumAmount(s.Points[len(s.Points)-half:]))
+		}
+		report.Scores = append(report.Scores, score)
+	}
+	if len(series) > 0 && len(series[0].Points) > 0 {
+		points := series[0].Points
+		report.Start, report.End = points[0].Date, points[len(points)-1].Date
+	}
+	for _, score := range report.Scores {
+		if report.Total.Amount > 0 {
+			score.Share = float64(score.Amount) / float64(report.Total.Amount)
+		}
+	}
+	report.Rank(options.RankBy)
+	return report, nil
+}
+
+// Rank 按指定依据重新排名，并列时按销售额、分销商 id 排序；增长率为空的排在最后
+func (r *DistributorReport) Rank(by RankBy) {
+	value := func(s *DistributorScore) float64 {
+		switch by {
+		case RankByTickets:
+			return float64(s.TicketCount)
+		case RankByOrders:
+			return float64(s.OrderCount)
+		case RankByGrowth:
+			if s.Growth == nil {
+				return math.Inf(-1)
+			}
+			return *s.Growth
+		case RankByConsistency:
+			return s.Consistency
+		case RankByAvgPrice:
+			return s.AvgPrice
+		}
+		return float64(s.Amount)
+	}
+	sort.SliceStable(r.Scores, func(i, j int) bool {
+		a, b := r.Scores[i], r.Scores[j]
+		if va, vb := value(a), value(b); va != vb {
+			return va > vb
+		}
+		if a.Amount != b.Amount {
+			return a.Amount > b.Amount
+		}
+		return a.DistributorId < b.DistributorId
+	})
+	for i, s := range r.Scores {
+		s.Rank = i + 1
+	}
+}
+
+// DropOffs 出现骤降的分销商，按排名顺序
+func (r *DistributorReport) DropOffs() []*DistributorScore {
+	var out []*DistributorScore
+	for _, s := range r.Scores {
+		if s.DropOff != nil {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// WriteCSV 导出评分卡，增长率为空时留空
+func (r *DistributorReport) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	_ = cw.Write([]string{"rank", "distributorId", "distributorName", "orderCount", "ticketCount", "amount",
+		"avgPrice", "share", "growth", "consistency", "activeDays", "dropOffSince", "dropOffChange"})
+	for _, s := range r.Scores {
+		growth, since, change := "", "", ""
+		if s.Growth != nil {
+			growth = strconv.FormatFloat(*s.Growth, 'f', 4, 64)
+		}
+		if s.DropOff != nil {
+			since = s.DropOff.Since
+			change = strconv.FormatFloat(s.DropOff.Change, 'f', 4, 64)
+		}
+		_ = cw.Write([]string{
+			strconv.Itoa(s.Rank),
+			strconv.Itoa(s.DistributorId),
+			s.DistributorName,
+			strconv.Itoa(s.OrderCount),
+			strconv.Itoa(s.TicketCount),
+			strconv.Itoa(s.Amount),
+			strconv.FormatFloat(s.AvgPrice, 'f', 2, 64),
+			strconv.FormatFloat(s.Share, 'f', 4, 64),
+			growth,
+			strconv.FormatFloat(s.Consistency, 'f', 4, 64),
+			strconv.Itoa(s.ActiveDays),
+			since,
+			change,
+		})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func sumAmount(points []*DistributorPoint) float64 {
+	var sum float64
+	for _, p := range points {
+		sum += float64(p.Amount)
+	}
+	return sum
+}
+
+func growth(before, after float64) *float64 {
+	if before <= 0 {
+		return nil
+	}
+	g := (after - before) / before
+	return &g
+}
+
+func consistency(points []*DistributorPoint) float64 {
+	if len(points) == 0 {
+		return 0
+	}
+	mean := sumAmount(points) / float64(len(points))
+	if mean <= 0 {
+		return 0
+	}
+	var variance float64
+	for _, p := range points {
+		d := float64(p.Amount) - mean
+		variance += d * d
+	}
+	cv := math.Sqrt(variance/float64(len(points))) / mean
+	return math.Max(0, 1-cv)
+}
+
+func dropOff(points []*DistributorPoint, options *ScorecardOptions) *DropOff {
+	window := options.DropWindow
+	if window <= 0 || len(points) <= window {
+		return nil
+	}
+	before, recent := points[:len(points)-window], points[len(points)-window:]
+	baseline := sumAmount(before) / float64(len(before))
+	if baseline <= 0 || baseline < options.DropMinAmount {
+		return nil
+	}
+	mean := sumAmount(recent) / float64(window)
+	change := (mean - baseline) / baseline
+	if change > -options.DropThreshold {
+		return nil
+	}
+	return &DropOff{Since: recent[0].Date, Baseline: baseline, Recent: mean, Change: change}
+}
@@ -0,0 +1,271 @@
+package channel
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/piaofutong/odas-sdk/odas"
+)
+
+// Other 接口按 Limit 截断后，合计中未列出的渠道归入的剩余行名称
+const Other = "其他"
+
+// MixMetric 计算占比与增长所用的指标
+type MixMetric int
+
+const (
+	MixByAmount MixMetric = iota
+	MixByTickets
+	MixByOrders
+)
+
+func (m MixMetric) of(t OrderChannelTotal) float64 {
+	switch m {
+	case MixByTickets:
+		return float64(t.TicketCount)
+	case MixByOrders:
+		return float64(t.OrderCount)
+	}
+	return float64(t.Amount)
+}
+
+// MixReq 渠道结构对比查询。ChannelClassId 大于 0 时对比该大类下的二级渠道，否则对比渠道大类；
+// CompareStart、CompareEnd 为空时按 Mode 计算对比周期，Mode 默认环比
+type MixReq struct {
+	odas.Req
+	odas.DateRangeCompareReq
+	Mode           odas.CompareMode
+	ChannelClassId int
+	Limit          int
+	Metric         MixMetric
+}
+
+// MixRow 单个渠道两期的对比。
+// Share 为占当期合计的比例，ShareShift 为占比变化；ChangeRate 在上期为 0 时为空；
+// Contribution 为对合计增长率的贡献(本渠道变化量/上期合计)，各行之和等于合计增长率
+type MixRow struct {
+	ChannelId     int               `json:"channelId"`
+	ChannelName   string            `json:"channelName"`
+	Current       OrderChannelTotal `json:"current"`
+	Previous      OrderChannelTotal `json:"previous"`
+	Share         float64           `json:"share"`
+	PreviousShare float64           `json:"previousShare"`
+	ShareShift    float64           `json:"shareShift"`
+	Change        float64           `json:"change"`
+	ChangeRate    *float64          `json:"changeRate"`
+	Contribution  float64           `json:"contribution"`
+	// ContributionShare 本渠道变化量占合计变化量的比例，合计无变化时为空
+	ContributionShare *float64 `json:"contributionShare"`
+	// Residual 为 true 表示合计中未列出渠道的剩余行，而非名为 "其他" 的渠道
+	Residual bool `json:"residual,omitempty"`
+}
+
+// MixReport 渠道结构对比结果，Rows 按贡献绝对值从大到小排序
+type MixReport struct {
+	Start        string            `json:"start"`
+	End          string            `json:"end"`
+	CompareStart string            `json:"compareStart"`
+	CompareEnd   string            `json:"compareEnd"`
+	Metric       MixMetric         `json:"metric"`
+	Current      OrderChannelTotal `json:"current"`
+	Previous     OrderChannelTotal `json:"previous"`
+	Change       float64           `json:"change"`
+	Growth       *float64          `json:"growth"`
+	Rows         []*MixRow         `json:"rows"`
+}
+
+// MixShift 分别查询当前周期与对比周期的渠道销售，计算渠道结构变化
+func MixShift(iam *odas.IAM, req *MixReq, opts ...odas.Option) (*MixReport, error) {
+	compareStart, compareEnd := req.CompareStart, req.CompareEnd
+	if compareStart == "" || compareEnd == "" {
+		mode := req.Mode
+		if mode == 0 {
+			mode = odas.CompareMom
+		}
+		var err error
+		if compareStart, compareEnd, err = odas.ComparePeriod(req.Start, req.End, mode); err != nil {
+			return nil, err
+		}
+	}
+	previousReq := req.Req
+	previousReq.Start, previousReq.End = compareStart, compareEnd
+
+	current, err := fetchMix(iam, &req.Req, req, opts)
+	if err != nil {
+		return nil, fmt.Errorf("current period: %w", err)
+	}
+	previous, err := fetchMix(iam, &previousReq, req, opts)
+	if err != nil {
+		return nil, fmt.Errorf("compare period: %w", err)
+	}
+	report := compareMix(current, previous, req.Metric)
+	report.Start, report.End = req.Start, req.End
+	report.CompareStart, report.CompareEnd = compareStart, compareEnd
+	return report, nil
+}
+
+// fetchMix 查询一期渠道销售，ChannelClassId 大于 0 时查询该大类下的二级渠道。
+// 二级渠道接口与渠道大类接口返回同一结构，ChannelClassId、ChannelClassName 为二级渠道的 id 与名称
+func fetchMix(iam *odas.IAM, base *odas.Req, req *MixReq, opts []odas.Option) (*mixPeriod, error) {
+	var request odas.IRequest = NewOrderFullChannelReq(base, WithLimit(req.Limit))
+	if req.ChannelClassId > 0 {
+		request = NewOrderSecondaryChannel(base, WithSecondaryChannelClassId(req.ChannelClassId), WithSecondaryChannelLimit(req.Limit))
+	}
+	var r OrderFullChannelResponse
+	if err := iam.Do(request, &r, opts...); err != nil {
+		return nil, err
+	}
+	return newMixPeriod(&r), nil
+}
+
+// mixPeriod 一期渠道销售，统一为 id、名称与合计
+type mixPeriod struct {
+	total *OrderChannelTotal
+	items []mixItem
+}
+
+type mixItem struct {
+	id   int
+	name string
+	OrderChannelTotal
+}
+
+func newMixPeriod(resp *OrderFullChannelResponse) *mixPeriod {
+	if resp == nil {
+		return nil
+	}
+	p := &mixPeriod{total: resp.Total}
+	for _, item := range resp.List {
+		p.items = append(p.items, mixItem{item.ChannelClassId, item.ChannelClassName,
+			OrderChannelTotal{OrderCount: item.OrderCount, TicketCount: item.TicketCount, Amount: item.Amount}})
+	}
+	return p
+}
+
+// CompareMix 对比两期渠道大类或二级渠道销售，渠道按 id 关联，id 缺失时按名称关联。
+// 响应带合计且大于列表之和时，差额计入单独的剩余行(Residual)，不与同名渠道合并
+func CompareMix(current, previous *OrderFullChannelResponse, metric MixMetric) *MixReport {
+	return compareMix(newMixPeriod(current), newMixPeriod(previous), metric)
+}
+
+func compareMix(current, previous *mixPeriod, metric MixMetric) *MixReport {
+	report := &MixReport{Metric: metric}
+	var (
+		byId     = make(map[int]*MixRow)
+		byName   = make(map[string]*MixRow)
+		order    []*MixRow
+		residual *MixRow
+	)
+	// lookup 先按 id 再按名称查找，两期中一期缺 id 时仍能关联
+	lookup := func(id int, name string) *MixRow {
+		name = strings.TrimSpace(name)
+		row, ok := byId[id]
+		if !ok || id == 0 {
+			row, ok = byName[name]
+			if ok && id > 0 && row.ChannelId > 0 && row.ChannelId != id {
+				ok = false
+			}
+		}
+		if !ok {
+			row = &MixRow{ChannelId: id, ChannelName: name}
+			order = append(order, row)
+		}
+		if row.ChannelId == 0 {
+			row.ChannelId = id
+		}
+		if row.ChannelName == "" {
+			row.ChannelName = name
+		}
+		if id > 0 {
+			byId[id] = row
+		}
+		if name != "" {
+			byName[name] = row
+		}
+		return row
+	}
+	add := func(p *mixPeriod, total *OrderChannelTotal, set func(row *MixRow, t OrderChannelTotal)) {
+		if p == nil {
+			return
+		}
+		var listed OrderChannelTotal
+		for _, item := range p.items {
+			set(lookup(item.id, item.name), item.OrderChannelTotal)
+			listed = addTotal(listed, item.OrderChannelTotal)
+		}
+		*total = listed
+		if p.total != nil && metric.of(*p.total) > metric.of(listed) {
+			*total = *p.total
+			if residual == nil {
+				residual = &MixRow{ChannelName: Other, Residual: true}
+				order = append(order, residual)
+			}
+			set(residual, OrderChannelTotal{
+				OrderCount:  max(p.total.OrderCount-listed.OrderCount, 0),
+				TicketCount: max(p.total.TicketCount-listed.TicketCount, 0),
+				Amount:      max(p.total.Amount-listed.Amount, 0),
+			})
+		}
+	}
+	add(current, &report.Current, func(row *MixRow, t OrderChannelTotal) { row.Current = addTotal(row.Current, t) })
+	add(previous, &report.Previous, func(row *MixRow, t OrderChannelTotal) { row.Previous = addTotal(row.Previous, t) })
+
+	cur, prev := metric.of(report.Current), metric.of(report.Previous)
+	report.Change = cur - prev
+	report.Growth = growth(prev, cur)
+	for _, row := range order {
+		c, p := metric.of(row.Current), metric.of(row.Previous)
+		if cur > 0 {
+			row.Share = c / cur
+		}
+		if prev > 0 {
+			row.PreviousShare = p / prev
+			row.Contribution = (c - p) / prev
+		}
+		row.ShareShift = row.Share - row.PreviousShare
+		row.Change = c - p
+		row.ChangeRate = growth(p, c)
+		if report.Change != 0 {
+			share := row.Change / report.Change
+			row.ContributionShare = &share
+		}
+		report.Rows = append(report.Rows, row)
+	}
+	sort.SliceStable(report.Rows, func(i, j int) bool {
+		return math.Abs(report.Rows[i].Change) > math.Abs(report.Rows[j].Change)
+	})
+	return report
+}
+
+// Gainers 占比上升的渠道，按占比变化从大到小排序
+func (r *MixReport) Gainers() []*MixRow {
+	return r.shifted(func(shift float64) bool { return shift > 0 })
+}
+
+// Losers 占比下降的渠道，按占比变化从小到大排序
+func (r *MixReport) Losers() []*MixRow {
+	return r.shifted(func(shift float64) bool { return shift < 0 })
+}
+
+func (r *MixReport) shifted(keep func(float64) bool) []*MixRow {
+	var out []*MixRow
+	for _, row := range r.Rows {
+		if keep(row.ShareShift) {
+			out = append(out, row)
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		return math.Abs(out[i].ShareShift) > math.Abs(out[j].ShareShift)
+	})
+	return out
+}
+
+func addTotal(a, b OrderChannelTotal) OrderChannelTotal {
+	return OrderChannelTotal{
+		OrderCount:  a.OrderCount + b.OrderCount,
+		TicketCount: a.TicketCount + b.TicketCount,
+		Amount:      a.Amount + b.Amount,
+	}
+}
@@ -0,0 +1,287 @@
+package channel
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/piaofutong/odas-sdk/odas"
+)
+
+// Level 渠道层级
+type Level int
+
+const (
+	LevelTotal Level = iota
+	LevelClass
+	LevelSecondary
+	LevelDistributor
+)
+
+func (l Level) String() string {
+	switch l {
+	case LevelTotal:
+		return "total"
+	case LevelClass:
+		return "class"
+	case LevelSecondary:
+		return "secondary"
+	case LevelDistributor:
+		return "distributor"
+	}
+	return fmt.Sprintf("Level(%d)", int(l))
+}
+
+// Node 渠道层级节点，合计取自本级接口；Rate 为销售额占上级的比例
+type Node struct {
+	OrderChannelTotal
+	Id    int
+	Name  string
+	Level Level
+	Rate  float64
+
+	tree     *Tree
+	parent   *Node
+	mutex    sync.Mutex
+	loaded   bool
+	children []*Node
+}
+
+// Tree 渠道大类 → 二级渠道 → 分销商的下钻树。
+// 根节点合计与渠道大类在首次访问时查询，二级渠道在调用 Children 时按需查询并缓存。
+// 分销商汇总接口不支持按渠道筛选，分销商无法归属到具体的二级渠道，
+// 由 Distributors 作为根节点下与渠道大类并列的未归属列表提供
+type Tree struct {
+	// Limit 渠道大类与二级渠道每级返回的条数，0 表示使用接口默认值
+	Limit int
+
+	iam  *odas.IAM
+	req  odas.Req
+	opts []odas.Option
+	root *Node
+
+	distMutex    sync.Mutex
+	distLoaded   bool
+	distributors []*Node
+}
+
+// NewTree 以 req 的景区、时间与门票筛选条件构建渠道树，opts 用于每次查询
+func NewTree(iam *odas.IAM, req *odas.Req, opts ...odas.Option) *Tree {
+	t := &Tree{iam: iam, req: *req, opts: opts}
+	t.root = &Node{Name: "合计", Level: LevelTotal, tree: t}
+	return t
+}
+
+// Root 返回根节点，合计在首次调用 Children 后填充
+func (t *Tree) Root() *Node {
+	return t.root
+}
+
+// Classes 渠道大类，等同于 Root().Children(ctx)
+func (t *Tree) Classes(ctx context.Context) ([]*Node, error) {
+	return t.root.Children(ctx)
+}
+
+// Distributors 分销商节点，上级为根节点，按销售额从大到小排序。
+// 分销商不归属任何渠道大类或二级渠道，不计入根节点的 Children 与 Unattributed；
+// 首次调用时查询接口，失败不缓存
+func (t *Tree) Distributors(ctx context.Context) ([]*Node, error) {
+	t.distMutex.Lock()
+	defer t.distMutex.Unlock()
+	if t.distLoaded {
+		return t.distributors, nil
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	nodes, err := t.fetchDistributors()
+	if err != nil {
+		return nil, fmt.Errorf("channel %s: %w", LevelDistributor, err)
+	}
+	sort.SliceStable(nodes, func(i, j int) bool { return nodes[i].Amount > nodes[j].Amount })
+	t.distributors, t.distLoaded = nodes, true
+	return nodes, nil
+}
+
+// Expand 预先加载 depth 层下级节点，同一层的节点并发查询；depth 大于 0 时同时加载分销商。
+// 部分节点失败时已加载的节点保留，返回各节点错误的合并
+func (t *Tree) Expand(ctx context.Context, depth int) error {
+	nodes := []*Node{t.root}
+	var (
+		errs    []error
+		distErr = make(chan error, 1)
+	)
+	if depth > 0 {
+		go func() {
+			_, err := t.Distributors(ctx)
+			distErr <- err
+		}()
+	} else {
+		distErr <- nil
+	}
+	for ; depth > 0 && len(nodes) > 0; depth-- {
+		var (
+			mutex sync.Mutex
+			wg    sync.WaitGroup
+			next  []*Node
+		)
+		for _, n := range nodes {
+			if n.Level >= LevelSecondary {
+				continue
+			}
+			wg.Add(1)
+			go func(n *Node) {
+				defer wg.Done()
+				children, err := n.Children(ctx)
+				mutex.Lock()
+				defer mutex.Unlock()
+				if err != nil {
+					errs = append(errs, err)
+					return
+				}
+				next = append(next, children...)
+			}(n)
+		}
+		wg.Wait()
+		nodes = next
+	}
+	if err := <-distErr; err != nil {
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+// Parent 上级节点，根节点返回 nil
+func (n *Node) Parent() *Node {
+	return n.parent
+}
+
+// Loaded 下级节点是否已查询
+func (n *Node) Loaded() bool {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+	return n.loaded
+}
+
+// Children 下级节点，按销售额从大到小排序。首次调用时查询接口，失败不缓存，下次调用重试；
+// 二级渠道与分销商节点没有下级，分销商见 Tree.Distributors
+func (n *Node) Children(ctx context.Context) ([]*Node, error) {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+	if n.loaded || n.Level >= LevelSecondary {
+		return n.children, nil
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	children, err := n.tree.fetch(n)
+	if err != nil {
+		return nil, fmt.Errorf("channel %s %s: %w", n.Level, n.Name, err)
+	}
+	sort.SliceStable(children, func(i, j int) bool { return children[i].Amount > children[j].Amount })
+	for _, c := range children {
+		c.tree, c.parent = n.tree, n
+		if n.Amount > 0 {
+			c.Rate = float64(c.Amount) / float64(n.Amount)
+		}
+	}
+	n.children, n.loaded = children, true
+	return children, nil
+}
+
+// Unattributed 本级合计中未分配到已加载下级的部分，如接口按 Limit 截断
+func (n *Node) Unattributed() OrderChannelTotal {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+	rest := n.OrderChannelTotal
+	for _, c := range n.children {
+		rest.OrderCount -= c.OrderCount
+		rest.TicketCount -= c.TicketCount
+		rest.Amount -= c.Amount
+	}
+	return rest
+}
+
+func (t *Tree) fetch(n *Node) ([]*Node, error) {
+	switch n.Level {
+	case LevelTotal:
+		return t.fetchClasses(n)
+	case LevelClass:
+		return t.fetchSecondary(n)
+	}
+	return nil, nil
+}
+
+// fetchClasses 查询渠道大类，同时填充根节点合计
+func (t *Tree) fetchClasses(root *Node) ([]*Node, error) {
+	var r OrderFullChannelResponse
+	if err := t.iam.Do(NewOrderFullChannelReq(&t.req, WithLimit(t.Limit)), &r, t.opts...); err != nil {
+		return nil, err
+	}
+	if r.Total != nil {
+		root.OrderChannelTotal = *r.Total
+	}
+	nodes := make([]*Node, 0, len(r.List))
+	for _, item := range r.List {
+		nodes = append(nodes, &Node{
+			OrderChannelTotal: OrderChannelTotal{OrderCount: item.OrderCount, TicketCount: item.TicketCount, Amount: item.Amount},
+			Id:                item.ChannelClassId,
+			Name:              item.ChannelClassName,
+			Level:             LevelClass,
+		})
+	}
+	return nodes, nil
+}
+
+// fetchSecondary 二级渠道接口与渠道大类接口返回同一结构，ChannelClassId、ChannelClassName 为二级渠道的 id 与名称
+func (t *Tree) fetchSecondary(class *Node) ([]*Node, error) {
+	var r OrderFullChannelResponse
+	req := NewOrderSecondaryChannel(&t.req, WithSecondaryChannelClassId(class.Id), WithSecondaryChannelLimit(t.Limit))
+	if err := t.iam.Do(req, &r, t.opts...); err != nil {
+		return nil, err
+	}
+	nodes := make([]*Node, 0, len(r.List))
+	for _, item := range r.List {
+		nodes = append(nodes, &Node{
+			OrderChannelTotal: OrderChannelTotal{OrderCount: item.OrderCount, TicketCount: item.TicketCount, Amount: item.Amount},
+			Id:                item.ChannelClassId,
+			Name:              item.ChannelClassName,
+			Level:             LevelSecondary,
+		})
+	}
+	return nodes, nil
+}
+
+// fetchDistributors 分销商接口按日返回，按分销商合并各日数据；Rate 为占全部分销商销售额的比例，
+// 分销商未归属渠道，不与渠道合计相比
+func (t *Tree) fetchDistributors() ([]*Node, error) {
+	var r []*StatDistributorSummaryResponse
+	if err := t.iam.Do(NewStatDistributorSummaryReq(&t.req), &r, t.opts...); err != nil {
+		return nil, err
+	}
+	index := make(map[int]*Node)
+	var nodes []*Node
+	for _, item := range r {
+		node, ok := index[item.DistributorID]
+		if !ok {
+			node = &Node{Id: item.DistributorID, Name: item.DistributorName, Level: LevelDistributor, tree: t, parent: t.root, loaded: true}
+			index[item.DistributorID] = node
+			nodes = append(nodes, node)
+		}
+		node.OrderCount += item.OrderCount
+		node.TicketCount += item.TicketCount
+		node.Amount += item.Amount
+	}
+	var total int
+	for _, n := range nodes {
+		total += n.Amount
+	}
+	if total > 0 {
+		for _, n := range nodes {
+			n.Rate = float64(n.Amount) / float64(total)
+		}
+	}
+	return nodes, nil
+}
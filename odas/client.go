@@ -39,28 +39,37 @@ func (o *IAM) SetBuilder(builder IBuilder) {
 	o.Builder = builder
 }
 
+// Do 发起请求，可被多个 goroutine 并发调用
 func (o *IAM) Do(req IRequest, v any, opts ...Option) error {
-	if o.Builder == nil {
-		o.mutex.Lock()
-		if o.Builder == nil {
-			o.Builder = NewBuilder(o.AccessKey)
-		}
-		o.mutex.Unlock()
-	}
 	var options = NewDoOption()
 	for _, opt := range opts {
 		opt(options)
 	}
-	if options.Token != "" {
-		o.Builder.WithToken(options.Token)
+	if validator, ok := req.(IValidator); ok {
+		if err := validator.Validate(); err != nil {
+			return err
+		}
 	}
-	request, err := o.Builder.Build(req)
+	request, err := o.build(req, options)
 	if err != nil {
 		return err
 	}
 	return o.Client.Do(request, v)
 }
 
+// build 设置 token 与构造请求需在同一把锁内完成，避免并发请求互相覆盖 token
+func (o *IAM) build(req IRequest, options *DoOption) (*http.Request, error) {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+	if o.Builder == nil {
+		o.Builder = NewBuilder(o.AccessKey)
+	}
+	if options.Token != "" {
+		o.Builder.WithToken(options.Token)
+	}
+	return o.Builder.Build(req)
+}
+
 func NewIAM(accessId, accessKey string) *IAM {
 	return &IAM{
 		AccessId:  accessId,
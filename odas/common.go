@@ -145,3 +145,10 @@ type InoutStatVO struct {
 	Employee    int `json:"employee"`     // 员工卡入园数
 	DeviceCount int `json:"device_count"` // 设备数
 }
+
+// Metric 指标值及其环比、同比
+type Metric struct {
+	Value int      `json:"value"`
+	Mom   *float64 `json:"mom"`
+	Yoy   *float64 `json:"yoy"`
+}
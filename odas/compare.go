@@ -0,0 +1,293 @@
+package odas
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+type CompareMode int
+
+const (
+	CompareMom CompareMode = iota + 1 // 环比，紧邻的上一周期
+	CompareYoy                        // 同比，去年同期
+)
+
+const (
+	dateLayout     = "2006-01-02"
+	dateTimeLayout = "2006-01-02 15:04:05"
+)
+
+// Shanghai 服务端日期与时间键所在时区
+var Shanghai = loadShanghai()
+
+func loadShanghai() *time.Location {
+	if loc, err := time.LoadLocation("Asia/Shanghai"); err == nil {
+		return loc
+	}
+	return time.FixedZone("Asia/Shanghai", 8*60*60)
+}
+
+// CompareRequest 对任意按日期查询的序列接口做同环比
+type CompareRequest[R any, T any] struct {
+	Start string
+	End   string
+	Mode  CompareMode
+	// Build 根据日期范围构造请求
+	Build func(start, end string) IRequest
+	// List 从响应中取出时间序列
+	List func(resp *R) []T
+	// Time 返回序列项所在的时间桶，为空时使用 Bucketed
+	Time func(item T) (time.Time, error)
+}
+
+func (r *CompareRequest[R, T]) timeOf(item T, day time.Time) (time.Time, error) {
+	if r.Time != nil {
+		return r.Time(item)
+	}
+	if b, ok := any(item).(Bucketed); ok {
+		bucket, err := b.Bucket(day)
+		return bucket.Time, err
+	}
+	return time.Time{}, fmt.Errorf("%T has no time bucket", item)
+}
+
+type CompareResult[T any] struct {
+	Mode         CompareMode
+	Start        string
+	End          string
+	CompareStart string
+	CompareEnd   string
+	Points       []*ComparePoint[T]
+}
+
+// ComparePoint 单个时间桶的对比结果，Growth 在对比值为 0 时为 nil
+type ComparePoint[T any] struct {
+	Time        time.Time
+	Current     T
+	Previous    T
+	HasPrevious bool
+	Delta       map[string]float64
+	Growth      map[string]*float64
+}
+
+// ComparePeriod 计算对比周期，日期按闭区间处理
+func ComparePeriod(start, end string, mode CompareMode) (string, string, error) {
+	s, layout, err := parseDate(start)
+	if err != nil {
+		return "", "", err
+	}
+	e, _, err := parseDate(end)
+	if err != nil {
+		return "", "", err
+	}
+	if e.Before(s) {
+		return "", "", fmt.Errorf("end %s before start %s", end, start)
+	}
+	unit := layoutUnit(layout)
+	switch mode {
+	case CompareMom:
+		if n := wholeMonths(s, e, unit); n > 0 {
+			return s.AddDate(0, -n, 0).Format(layout), s.Add(-unit).Format(layout), nil
+		}
+		period := e.Sub(s) + unit
+		return s.Add(-period).Format(layout), s.Add(-unit).Format(layout), nil
+	case CompareYoy:
+		return yearAgo(s).Format(layout), yearAgo(e).Format(layout), nil
+	}
+	return "", "", fmt.Errorf("unknown compare mode %d", mode)
+}
+
+func parseDate(v string) (time.Time, string, error) {
+	for _, layout := range []string{dateLayout, dateTimeLayout} {
+		if t, err := time.ParseInLocation(layout, v, Shanghai); err == nil {
+			return t, layout, nil
+		}
+	}
+	return time.Time{}, "", fmt.Errorf("invalid date %q", v)
+}
+
+// yearAgo 去年同日，闰年 2 月 29 日对应 2 月 28 日
+func yearAgo(t time.Time) time.Time {
+	y := t.AddDate(-1, 0, 0)
+	if y.Month() != t.Month() {
+		y = y.AddDate(0, 0, -y.Day())
+	}
+	return y
+}
+
+// layoutUnit 日期格式的最小单位，闭区间端点按该单位计算
+func layoutUnit(layout string) time.Duration {
+	if layout == dateLayout {
+		return 24 * time.Hour
+	}
+	return time.Second
+}
+
+// wholeMonths 周期恰好覆盖整自然月时返回月数，否则返回 0
+func wholeMonths(s, e time.Time, unit time.Duration) int {
+	next := e.Add(unit)
+	if s.Day() != 1 || !isMidnight(s) || next.Day() != 1 || !isMidnight(next) {
+		return 0
+	}
+	return (next.Year()-s.Year())*12 + int(next.Month()-s.Month())
+}
+
+func isMidnight(t time.Time) bool {
+	return t.Hour() == 0 && t.Minute() == 0 && t.Second() == 0 && t.Nanosecond() == 0
+}
+
+// shiftBack 将当前周期的时间桶映射到对比周期。months 大于 0 时环比按自然月平移，
+// 对比月没有对应日期(如 3 月 31 日对 2 月)时返回 false
+func shiftBack(t time.Time, mode CompareMode, offset time.Duration, months int) (time.Time, bool) {
+	switch {
+	case mode == CompareYoy:
+		return yearAgo(t), true
+	case months > 0:
+		p := t.AddDate(0, -months, 0)
+		return p, p.Day() == t.Day()
+	}
+	return t.Add(-offset), true
+}
+
+// Compare 分别查询当前周期与对比周期，并按时间桶对齐计算差值与增长率
+func Compare[R any, T any](iam *IAM, req *CompareRequest[R, T], opts ...Option) (*CompareResult[T], error) {
+	compareStart, compareEnd, err := ComparePeriod(req.Start, req.End, req.Mode)
+	if err != nil {
+		return nil, err
+	}
+	var current, previous R
+	if err = iam.Do(req.Build(req.Start, req.End), &current, opts...); err != nil {
+		return nil, err
+	}
+	if err = iam.Do(req.Build(compareStart, compareEnd), &previous, opts...); err != nil {
+		return nil, err
+	}
+
+	s, layout, _ := parseDate(req.Start)
+	e, _, _ := parseDate(req.End)
+	cs, _, _ := parseDate(compareStart)
+	offset := s.Sub(cs)
+	var months int
+	if req.Mode == CompareMom {
+		months = wholeMonths(s, e, layoutUnit(layout))
+	}
+
+	prior := make(map[int64]T)
+	for _, item := range req.List(&previous) {
+		t, err := req.timeOf(item, cs)
+		if err != nil {
+			return nil, err
+		}
+		prior[t.Unix()] = item
+	}
+
+	result := &CompareResult[T]{
+		Mode:         req.Mode,
+		Start:        req.Start,
+		End:          req.End,
+		CompareStart: compareStart,
+		CompareEnd:   compareEnd,
+	}
+	for _, item := range req.List(&current) {
+		t, err := req.timeOf(item, s)
+		if err != nil {
+			return nil, err
+		}
+		point := &ComparePoint[T]{Time: t, Current: item}
+		if p, ok := shiftBack(t, req.Mode, offset, months); ok {
+			point.Previous, point.HasPrevious = prior[p.Unix()]
+		}
+		point.Delta, point.Growth = diffFields(item, point.Previous)
+		result.Points = append(result.Points, point)
+	}
+	return result, nil
+}
+
+func diffFields(current, previous any) (map[string]float64, map[string]*float64) {
+	cur, prev := NumericFields(current), NumericFields(previous)
+	delta := make(map[string]float64, len(cur))
+	growth := make(map[string]*float64, len(cur))
+	for k, v := range cur {
+		p := prev[k]
+		delta[k] = v - p
+		if p != 0 {
+			g := (v - p) / p
+			growth[k] = &g
+		} else {
+			growth[k] = nil
+		}
+	}
+	return delta, growth
+}
+
+// timeFieldKeys 时间桶字段不参与数值对比
+var timeFieldKeys = map[string]bool{"time": true, "date": true, "hour": true}
+
+// NumericFields 按 json 字段名取出结构体(含嵌入结构体)中的数值字段
+func NumericFields(v any) map[string]float64 {
+	out := make(map[string]float64)
+	collectNumeric(reflect.ValueOf(v), out)
+	return out
+}
+
+func collectNumeric(v reflect.Value, out map[string]float64) {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return
+	}
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		if field.Anonymous {
+			collectNumeric(v.Field(i), out)
+			continue
+		}
+		name := field.Name
+		if tag, _, _ := strings.Cut(field.Tag.Get("json"), ","); tag != "" && tag != "-" {
+			name = tag
+		}
+		if timeFieldKeys[name] {
+			continue
+		}
+		if f, ok := numericValue(v.Field(i)); ok {
+			out[name] = f
+		}
+	}
+}
+
+func numericValue(v reflect.Value) (float64, bool) {
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return 0, isNumericKind(v.Type().Elem().Kind())
+		}
+		v = v.Elem()
+	}
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), true
+	}
+	return 0, false
+}
+
+func isNumericKind(k reflect.Kind) bool {
+	switch k {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
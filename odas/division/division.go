@@ -0,0 +1,225 @@
+package division
+
+import (
+	_ "embed"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+//go:embed divisions.csv
+var builtinDivisions string
+
+// China 国家节点名称
+const China = "中国"
+
+var ErrAmbiguous = errors.New("division: ambiguous name")
+
+type Level int
+
+const (
+	LevelTotal Level = iota
+	LevelCountry
+	LevelProvince
+	LevelCity
+	LevelDistrict
+)
+
+func (l Level) String() string {
+	switch l {
+	case LevelTotal:
+		return "total"
+	case LevelCountry:
+		return "country"
+	case LevelProvince:
+		return "province"
+	case LevelCity:
+		return "city"
+	case LevelDistrict:
+		return "district"
+	}
+	return fmt.Sprintf("Level(%d)", int(l))
+}
+
+// suffixes 行政区划名称后缀，较长的在前
+var suffixes = []string{
+	"特别行政区", "维吾尔自治区", "壮族自治区", "回族自治区", "自治区", "自治州", "自治县", "自治旗",
+	"地区", "新区", "林区", "省", "市", "区", "县", "盟", "旗",
+}
+
+// ethnicities 自治州、自治县名称中的民族名，去掉后缀后继续剥离，如 "红河哈尼族彝族" -> "红河"
+var ethnicities = []string{
+	"柯尔克孜", "哈萨克", "蒙古族", "朝鲜族", "土家族", "布依族", "哈尼族", "景颇族", "傈僳族",
+	"蒙古", "苗族", "藏族", "羌族", "彝族", "侗族", "壮族", "傣族", "白族", "回族", "黎族",
+}
+
+// placeholders 数据源中表示"无此级"的占位名称
+var placeholders = map[string]bool{
+	"市辖区": true, "县": true, "省直辖县级行政区划": true, "自治区直辖县级行政区划": true,
+}
+
+// Normalize 统一行政区划名称的写法，去掉 "省"、"市"、"自治州" 等后缀与民族名，
+// 如 "浙江省"、"浙江" 均得到 "浙江"，"延边朝鲜族自治州" 得到 "延边"。占位名称如 "市辖区" 返回空
+func Normalize(name string) string {
+	name = strings.TrimSpace(name)
+	if placeholders[name] {
+		return ""
+	}
+	for _, suffix := range suffixes {
+		if rest := strings.TrimSuffix(name, suffix); rest != name && utf8.RuneCountInString(rest) >= 2 {
+			name = rest
+			break
+		}
+	}
+	for stripped := true; stripped; {
+		stripped = false
+		for _, e := range ethnicities {
+			if rest := strings.TrimSuffix(name, e); rest != name && utf8.RuneCountInString(rest) >= 2 {
+				name, stripped = rest, true
+				break
+			}
+		}
+	}
+	return name
+}
+
+// Division 行政区划节点，Name 为规范全称
+type Division struct {
+	Name     string
+	Level    Level
+	Parent   *Division
+	Children []*Division
+}
+
+// Tree 国内省、市两级行政区划，区县按数据中出现的名称动态挂载
+type Tree struct {
+	mutex     sync.RWMutex
+	provinces map[string]*Division
+	cities    map[string][]*Division
+	list      []*Division
+}
+
+func NewTree() *Tree {
+	return &Tree{
+		provinces: make(map[string]*Division),
+		cities:    make(map[string][]*Division),
+	}
+}
+
+var (
+	defaultOnce sync.Once
+	defaultTree *Tree
+)
+
+// Default 内置行政区划数据的共享区划树
+func Default() *Tree {
+	defaultOnce.Do(func() {
+		defaultTree = NewTree()
+		if err := defaultTree.Load(strings.NewReader(builtinDivisions)); err != nil {
+			panic(err)
+		}
+	})
+	return defaultTree
+}
+
+// Load 从 CSV 读取省市对应，首行为表头 province,city，使用规范全称，格式同内置数据
+func (t *Tree) Load(r io.Reader) error {
+	records, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		return err
+	}
+	for i, record := range records {
+		if i == 0 && record[0] == "province" {
+			continue
+		}
+		if len(record) != 2 {
+			return fmt.Errorf("divisions line %d: want 2 fields, got %d", i+1, len(record))
+		}
+		t.Register(record[0], record[1])
+	}
+	return nil
+}
+
+// Register 登记省份及其下属城市，city 为空时只登记省份
+func (t *Tree) Register(province, city string) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	key := Normalize(province)
+	p, ok := t.provinces[key]
+	if !ok {
+		p = &Division{Name: strings.TrimSpace(province), Level: LevelProvince}
+		t.provinces[key] = p
+		t.list = append(t.list, p)
+	}
+	if Normalize(city) == "" {
+		return
+	}
+	cityKey := Normalize(city)
+	for _, c := range p.Children {
+		if Normalize(c.Name) == cityKey {
+			return
+		}
+	}
+	c := &Division{Name: strings.TrimSpace(city), Level: LevelCity, Parent: p}
+	p.Children = append(p.Children, c)
+	t.cities[cityKey] = append(t.cities[cityKey], c)
+}
+
+// Provinces 全部省级区划，按登记顺序
+func (t *Tree) Provinces() []*Division {
+	t.mutex.RLock()
+	defer t.mutex.RUnlock()
+	return append([]*Division(nil), t.list...)
+}
+
+// Province 按名称查找省级区划，名称可带或不带后缀
+func (t *Tree) Province(name string) (*Division, bool) {
+	t.mutex.RLock()
+	defer t.mutex.RUnlock()
+	p, ok := t.provinces[Normalize(name)]
+	return p, ok
+}
+
+// City 按名称查找城市；province 为空时在全国范围查找，重名时返回 ErrAmbiguous。
+// 未找到时返回 nil, nil
+func (t *Tree) City(province, city string) (*Division, error) {
+	key := Normalize(city)
+	if key == "" {
+		return nil, nil
+	}
+	t.mutex.RLock()
+	defer t.mutex.RUnlock()
+	matches := t.cities[key]
+	if province != "" {
+		p, ok := t.provinces[Normalize(province)]
+		if !ok {
+			return nil, nil
+		}
+		for _, c := range matches {
+			if c.Parent == p {
+				return c, nil
+			}
+		}
+		return nil, nil
+	}
+	switch len(matches) {
+	case 0:
+		return nil, nil
+	case 1:
+		return matches[0], nil
+	}
+	return nil, fmt.Errorf("%w: %s", ErrAmbiguous, city)
+}
+
+// IsChina 国家名称是否指中国，空值按中国处理
+func IsChina(country string) bool {
+	switch strings.TrimSpace(country) {
+	case "", China, "中国大陆", "中华人民共和国", "CN", "China":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,64 @@
+package odas
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// FillGaps 按请求的起止日期与粒度补齐缺失的时间桶，缺失项由 zero 构造，结果按时间升序。
+// 日期格式的 end 包含当天整天。只有时分的时间键(如 "08:00")仅在起止同一天时落在该天，
+// 跨天范围内无法确定所属日期，返回错误。
+func FillGaps[T Bucketed](list []T, start, end string, granularity Granularity, zero func(b Bucket) T) ([]T, error) {
+	s, _, err := parseDate(start)
+	if err != nil {
+		return nil, err
+	}
+	e, layout, err := parseDate(end)
+	if err != nil {
+		return nil, err
+	}
+	if layout == dateLayout {
+		e = e.AddDate(0, 0, 1).Add(-time.Nanosecond)
+	}
+
+	// 跨天时不提供日期，只有时分的时间键解析失败，避免多天数据合并到第一天
+	day := s
+	if !sameDay(s, e) {
+		day = time.Time{}
+	}
+
+	type entry struct {
+		at   time.Time
+		item T
+	}
+	entries := make([]entry, 0, len(list))
+	seen := make(map[int64]bool, len(list))
+	for _, item := range list {
+		b, err := item.Bucket(day)
+		if err != nil {
+			return nil, fmt.Errorf("range %s ~ %s: %w", start, end, err)
+		}
+		seen[b.Time.Unix()] = true
+		entries = append(entries, entry{at: b.Time, item: item})
+	}
+	for b := NewBucket(s, granularity); !b.Time.After(e); b = b.Next() {
+		if !seen[b.Time.Unix()] {
+			entries = append(entries, entry{at: b.Time, item: zero(b)})
+		}
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].at.Before(entries[j].at)
+	})
+
+	out := make([]T, len(entries))
+	for i, en := range entries {
+		out[i] = en.item
+	}
+	return out, nil
+}
+
+func sameDay(a, b time.Time) bool {
+	a, b = a.In(Shanghai), b.In(Shanghai)
+	return a.Year() == b.Year() && a.YearDay() == b.YearDay()
+}
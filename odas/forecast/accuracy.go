@@ -0,0 +1,308 @@
+package forecast
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/piaofutong/odas-sdk/odas"
+	"github.com/piaofutong/odas-sdk/odas/tourist"
+)
+
+// Snapshot 某一时刻保存的服务端预测结果
+type Snapshot struct {
+	Sid     int                                           `json:"sid"`
+	TakenAt time.Time                                     `json:"takenAt"`
+	Start   string                                        `json:"start"`
+	End     string                                        `json:"end"`
+	List    *tourist.ForecastPassengerFlowListResponse    `json:"list,omitempty"`
+	Summary *tourist.ForecastPassengerFlowSummaryResponse `json:"summary,omitempty"`
+}
+
+// SnapshotStore 预测快照存储
+type SnapshotStore interface {
+	Save(s *Snapshot) error
+	Load() ([]*Snapshot, error)
+}
+
+// MemoryStore 内存快照存储
+type MemoryStore struct {
+	mutex     sync.Mutex
+	snapshots []*Snapshot
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{}
+}
+
+func (s *MemoryStore) Save(snapshot *Snapshot) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.snapshots = append(s.snapshots, snapshot)
+	return nil
+}
+
+func (s *MemoryStore) Load() ([]*Snapshot, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return append([]*Snapshot(nil), s.snapshots...), nil
+}
+
+// FileStore 以 JSON Lines 追加写入文件的快照存储
+type FileStore struct {
+	mutex sync.Mutex
+	Path  string
+}
+
+func NewFileStore(path string) *FileStore {
+	return &FileStore{Path: path}
+}
+
+func (s *FileStore) Save(snapshot *Snapshot) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	b, err := json.Marshal(snapshot)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(s.Path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err = f.Write(append(b, '\n')); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func (s *FileStore) Load() ([]*Snapshot, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	f, err := os.Open(s.Path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+	var out []*Snapshot
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var snapshot Snapshot
+		if err = json.Unmarshal(scanner.Bytes(), &snapshot); err != nil {
+			return nil, err
+		}
+		out = append(out, &snapshot)
+	}
+	return out, scanner.Err()
+}
+
+// Actuals 日期(yyyy-mm-dd)到实际客流
+type Actuals map[string]float64
+
+// ActualsFromPassengerFlow 取 DailyPassengerFlowReq 响应中的逐日客流
+func ActualsFromPassengerFlow(r *tourist.PassengerFlowByDateResponse) (Actuals, error) {
+	obs, err := FromPassengerFlow(r)
+	if err != nil {
+		return nil, err
+	}
+	a := make(Actuals, len(obs))
+	for _, o := range obs {
+		a[o.Date.Format(dateLayout)] = o.Count
+	}
+	return a, nil
+}
+
+// ActualsFromInout 取 SummaryByDateReq 响应中的逐日入园数
+func ActualsFromInout(r *tourist.InoutSummaryResponse) (Actuals, error) {
+	a := make(Actuals, len(r.List))
+	for _, item := range r.List {
+		b, err := item.Bucket(time.Time{})
+		if err != nil {
+			return nil, err
+		}
+		a[b.Time.Format(dateLayout)] += float64(item.In)
+	}
+	return a, nil
+}
+
+// Tracker 保存服务端预测快照，并与实际客流对比评估准确度
+type Tracker struct {
+	store SnapshotStore
+}
+
+func NewTracker(store SnapshotStore) *Tracker {
+	return &Tracker{store: store}
+}
+
+// Capture 拉取并保存当前的预测列表与预测汇总
+func (t *Tracker) Capture(iam *odas.IAM, sid int, start, end string, opts ...odas.Option) (*Snapshot, error) {
+	snapshot := &Snapshot{
+		Sid:     sid,
+		TakenAt: time.Now().In(odas.Shanghai),
+		Start:   start,
+		End:     end,
+		List:    &tourist.ForecastPassengerFlowListResponse{},
+		Summary: &tourist.ForecastPassengerFlowSummaryResponse{},
+	}
+	if err := iam.Do(tourist.NewForecastPassengerFlowListReq(start, end, "", "", sid, 0), snapshot.List, opts...); err != nil {
+		return nil, err
+	}
+	if err := iam.Do(tourist.NewForecastPassengerFlowSummaryReq(start, end, "", "", sid, 0), snapshot.Summary, opts...); err != nil {
+		return nil, err
+	}
+	return snapshot, t.store.Save(snapshot)
+}
+
+// Record 保存外部获取的快照
+func (t *Tracker) Record(snapshot *Snapshot) error {
+	return t.store.Save(snapshot)
+}
+
+const (
+	KindDaily   = "daily"   // 今日/明日/后日预测
+	KindSummary = "summary" // 时间段汇总预测
+)
+
+// AccuracyRow 某景区在某预测步长下的准确度，Horizon 为快照日期到目标日期(或时间段开始)的天数
+type AccuracyRow struct {
+	Sid     int     `json:"sid"`
+	Kind    string  `json:"kind"`
+	Horizon int     `json:"horizon"`
+	Samples int     `json:"samples"`
+	MAPE    float64 `json:"mape"`
+	Bias    float64 `json:"bias"`
+}
+
+type AccuracyReport struct {
+	Rows []*AccuracyRow `json:"rows"`
+	// Skipped 缺少实际值或实际值为 0 而未参与评估的样本数
+	Skipped int `json:"skipped"`
+}
+
+type accuracyKey struct {
+	sid     int
+	kind    string
+	horizon int
+}
+
+// Evaluate 将所有快照与各景区的实际客流对比，按景区、类型与步长汇总 MAPE 和偏差
+func (t *Tracker) Evaluate(actuals map[int]Actuals) (*AccuracyReport, error) {
+	snapshots, err := t.store.Load()
+	if err != nil {
+		return nil, err
+	}
+	type acc struct{ ape, bias, n float64 }
+	stats := make(map[accuracyKey]*acc)
+	report := &AccuracyReport{}
+	add := func(key accuracyKey, forecast, actual float64, ok bool) {
+		if !ok || actual == 0 {
+			report.Skipped++
+			return
+		}
+		s, found := stats[key]
+		if !found {
+			s = &acc{}
+			stats[key] = s
+		}
+		s.ape += math.Abs(forecast-actual) / actual
+		s.bias += (forecast - actual) / actual
+		s.n++
+	}
+
+	for _, snapshot := range snapshots {
+		actual := actuals[snapshot.Sid]
+		day := odas.NewBucket(snapshot.TakenAt, odas.GranularityDay).Time
+		if snapshot.List != nil && snapshot.List.Total != nil {
+			total := snapshot.List.Total
+			for h, v := range []int{total.TodayFlow, total.TomorrowFlow, total.ThirdDayFlow} {
+				a, ok := actual[day.AddDate(0, 0, h).Format(dateLayout)]
+				add(accuracyKey{snapshot.Sid, KindDaily, h}, float64(v), a, ok)
+			}
+		}
+		if snapshot.Summary != nil {
+			a, ok := sumRange(actual, snapshot.Start, snapshot.End)
+			start, err := time.ParseInLocation(dateLayout, snapshot.Start, odas.Shanghai)
+			if err != nil {
+				return nil, fmt.Errorf("snapshot start %q: %w", snapshot.Start, err)
+			}
+			h := int(math.Round(start.Sub(day).Hours() / 24))
+			add(accuracyKey{snapshot.Sid, KindSummary, h}, float64(snapshot.Summary.Total), a, ok)
+		}
+	}
+
+	for key, s := range stats {
+		report.Rows = append(report.Rows, &AccuracyRow{
+			Sid:     key.sid,
+			Kind:    key.kind,
+			Horizon: key.horizon,
+			Samples: int(s.n),
+			MAPE:    s.ape / s.n,
+			Bias:    s.bias / s.n,
+		})
+	}
+	sort.Slice(report.Rows, func(i, j int) bool {
+		a, b := report.Rows[i], report.Rows[j]
+		if a.Sid != b.Sid {
+			return a.Sid < b.Sid
+		}
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		return a.Horizon < b.Horizon
+	})
+	return report, nil
+}
+
+// sumRange 时间段内实际客流合计，任一日期缺失时返回 false
+func sumRange(actual Actuals, start, end string) (float64, bool) {
+	s, err := time.ParseInLocation(dateLayout, start, odas.Shanghai)
+	if err != nil {
+		return 0, false
+	}
+	e, err := time.ParseInLocation(dateLayout, end, odas.Shanghai)
+	if err != nil || e.Before(s) {
+		return 0, false
+	}
+	var sum float64
+	for d := s; !d.After(e); d = d.AddDate(0, 0, 1) {
+		v, ok := actual[d.Format(dateLayout)]
+		if !ok {
+			return 0, false
+		}
+		sum += v
+	}
+	return sum, true
+}
+
+// WriteCSV 导出准确度报告
+func (r *AccuracyReport) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	_ = cw.Write([]string{"sid", "kind", "horizon", "samples", "mape", "bias"})
+	for _, row := range r.Rows {
+		_ = cw.Write([]string{
+			strconv.Itoa(row.Sid),
+			row.Kind,
+			strconv.Itoa(row.Horizon),
+			strconv.Itoa(row.Samples),
+			strconv.FormatFloat(row.MAPE, 'f', 4, 64),
+			strconv.FormatFloat(row.Bias, 'f', 4, 64),
+		})
+	}
+	cw.Flush()
+	return cw.Error()
+}
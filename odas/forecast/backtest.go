@@ -0,0 +1,80 @@
+package forecast
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// BacktestResult 滚动回测结果
+type BacktestResult struct {
+	Folds   int
+	Horizon int
+	// MAPE 平均绝对百分比误差，实际值为 0 的日期不计入
+	MAPE float64
+	// Bias 平均(预测-实际)/实际，正数表示整体高估
+	Bias float64
+	// Coverage 实际值落在置信区间内的比例
+	Coverage float64
+	Points   []*BacktestPoint
+}
+
+type BacktestPoint struct {
+	Origin time.Time
+	Point
+	Actual float64
+}
+
+// Backtest 以最后 folds 个长度为 horizon 的窗口做滚动回测：每个窗口只用此前的数据拟合
+func Backtest(history []Observation, horizon, folds int, opt ...Option) (*BacktestResult, error) {
+	obs := make([]Observation, len(history))
+	copy(obs, history)
+	sort.Slice(obs, func(i, j int) bool { return obs[i].Date.Before(obs[j].Date) })
+	if horizon <= 0 || folds <= 0 {
+		return nil, fmt.Errorf("horizon and folds must be positive")
+	}
+	if len(obs)-horizon*folds < 14 {
+		return nil, fmt.Errorf("history too short for %d folds of %d days", folds, horizon)
+	}
+
+	actual := make(map[string]float64, len(obs))
+	for _, o := range obs {
+		actual[o.Date.Format(dateLayout)] = o.Count
+	}
+
+	result := &BacktestResult{Folds: folds, Horizon: horizon}
+	var ape, bias, covered, n, total float64
+	for f := folds; f >= 1; f-- {
+		train := obs[:len(obs)-horizon*f]
+		m, err := Fit(train, opt...)
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range m.Predict(horizon) {
+			a, ok := actual[p.Date.Format(dateLayout)]
+			if !ok {
+				continue
+			}
+			result.Points = append(result.Points, &BacktestPoint{Origin: m.Last, Point: *p, Actual: a})
+			total++
+			if a >= p.Lower && a <= p.Upper {
+				covered++
+			}
+			if a == 0 {
+				continue
+			}
+			ape += math.Abs(p.Value-a) / a
+			bias += (p.Value - a) / a
+			n++
+		}
+	}
+	if n > 0 {
+		result.MAPE = ape / n
+		result.Bias = bias / n
+	}
+	if total > 0 {
+		result.Coverage = covered / total
+	}
+	return result, nil
+}
@@ -0,0 +1,287 @@
+package forecast
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/piaofutong/odas-sdk/odas"
+	"github.com/piaofutong/odas-sdk/odas/gadget"
+	"github.com/piaofutong/odas-sdk/odas/tourist"
+)
+
+const dateLayout = "2006-01-02"
+
+// rainThreshold 日降水量达到该值(mm)视为雨天
+const rainThreshold = 1.0
+
+// Observation 单日实际客流
+type Observation struct {
+	Date  time.Time
+	Count float64
+}
+
+// Point 单日预测值及置信区间
+type Point struct {
+	Date    time.Time
+	Value   float64
+	Lower   float64
+	Upper   float64
+	Holiday bool
+}
+
+// Weather 日期(yyyy-mm-dd)到日降水量(mm)
+type Weather map[string]float64
+
+// WeatherFromForecast 取天气预报中的逐日降水量
+func WeatherFromForecast(list []gadget.WeatherForecast) Weather {
+	w := make(Weather, len(list))
+	for _, f := range list {
+		if v, err := f.PrecipMm(); err == nil {
+			w[f.FxDate] = v
+		}
+	}
+	return w
+}
+
+type Options struct {
+	Window  int
+	Z       float64
+	Holiday func(date time.Time) bool
+	Weather Weather
+}
+
+type Option func(options *Options)
+
+// WithWindow 用于估计基线水平的最近天数，默认 56 天
+func WithWindow(days int) Option {
+	return func(options *Options) {
+		options.Window = days
+	}
+}
+
+// WithConfidence 置信水平，支持 0.8、0.9、0.95、0.99，默认 0.9
+func WithConfidence(level float64) Option {
+	return func(options *Options) {
+		switch {
+		case level >= 0.99:
+			options.Z = 2.576
+		case level >= 0.95:
+			options.Z = 1.96
+		case level >= 0.9:
+			options.Z = 1.645
+		default:
+			options.Z = 1.282
+		}
+	}
+}
+
+// WithHolidays 节假日判断，节假日单独估计放大系数
+func WithHolidays(fn func(date time.Time) bool) Option {
+	return func(options *Options) {
+		options.Holiday = fn
+	}
+}
+
+// WithWeather 以降水量作为协变量，需同时覆盖历史与预测日期
+func WithWeather(w Weather) Option {
+	return func(options *Options) {
+		options.Weather = w
+	}
+}
+
+func newOptions(opt []Option) *Options {
+	options := &Options{Window: 56, Z: 1.645}
+	for _, p := range opt {
+		p(options)
+	}
+	return options
+}
+
+// Model 乘法季节模型：基线 × 星期系数 × 节假日系数 × 雨天系数
+type Model struct {
+	options *Options
+	Last    time.Time
+	Level   float64
+	Weekday [7]float64
+	Holiday float64
+	Rain    float64
+	// Sigma 对数残差标准差
+	Sigma float64
+}
+
+// FromPassengerFlow 将 DailyPassengerFlowReq 的响应转换为观测序列
+func FromPassengerFlow(r *tourist.PassengerFlowByDateResponse) ([]Observation, error) {
+	out := make([]Observation, 0, len(r.List))
+	for _, item := range r.List {
+		b, err := item.Bucket(time.Time{})
+		if err != nil {
+			return nil, err
+		}
+		day := odas.NewBucket(b.Time, odas.GranularityDay)
+		out = append(out, Observation{Date: day.Time, Count: float64(item.Count)})
+	}
+	return out, nil
+}
+
+// FetchHistory 通过 DailyPassengerFlowReq 拉取历史客流
+func FetchHistory(iam *odas.IAM, req *odas.Req, opts ...odas.Option) ([]Observation, error) {
+	var r tourist.PassengerFlowByDateResponse
+	if err := iam.Do(tourist.NewDailyPassengerFlowReq(req, false), &r, opts...); err != nil {
+		return nil, err
+	}
+	return FromPassengerFlow(&r)
+}
+
+// Fit 在历史数据上拟合模型，至少需要两周数据
+func Fit(history []Observation, opt ...Option) (*Model, error) {
+	options := newOptions(opt)
+	if len(history) < 14 {
+		return nil, fmt.Errorf("need at least 14 days of history, got %d", len(history))
+	}
+	obs := make([]Observation, len(history))
+	copy(obs, history)
+	sort.Slice(obs, func(i, j int) bool { return obs[i].Date.Before(obs[j].Date) })
+
+	m := &Model{options: options, Last: obs[len(obs)-1].Date, Holiday: 1, Rain: 1}
+
+	// 星期系数只使用非节假日数据
+	var sums, counts [7]float64
+	var total, n float64
+	for _, o := range obs {
+		if m.isHoliday(o.Date) {
+			continue
+		}
+		d := o.Date.Weekday()
+		sums[d] += o.Count
+		counts[d]++
+		total += o.Count
+		n++
+	}
+	if n == 0 || total == 0 {
+		return nil, fmt.Errorf("history has no regular-day traffic")
+	}
+	overall := total / n
+	for d := range m.Weekday {
+		m.Weekday[d] = 1
+		if counts[d] > 0 {
+			m.Weekday[d] = sums[d] / counts[d] / overall
+		}
+	}
+
+	// 基线水平取最近窗口内去季节化后的均值
+	window := obs
+	if len(window) > options.Window {
+		window = window[len(window)-options.Window:]
+	}
+	var level, ln float64
+	for _, o := range window {
+		if m.isHoliday(o.Date) || m.Weekday[o.Date.Weekday()] == 0 {
+			continue
+		}
+		level += o.Count / m.Weekday[o.Date.Weekday()]
+		ln++
+	}
+	if ln == 0 {
+		level, ln = overall, 1
+	}
+	m.Level = level / ln
+
+	m.Holiday = m.ratio(obs, func(o Observation) bool { return m.isHoliday(o.Date) }, false)
+	if options.Weather != nil {
+		m.Rain = m.ratio(obs, func(o Observation) bool { return m.isRainy(o.Date) }, true)
+	}
+
+	var sq, sn float64
+	for _, o := range window {
+		fitted := m.expected(o.Date)
+		if o.Count <= 0 || fitted <= 0 {
+			continue
+		}
+		r := math.Log(o.Count / fitted)
+		sq += r * r
+		sn++
+	}
+	m.Sigma = 0.05
+	if sn > 1 {
+		m.Sigma = math.Max(m.Sigma, math.Sqrt(sq/(sn-1)))
+	}
+	return m, nil
+}
+
+// ratio 满足条件的日期实际值与不含该因素的预期值之比的均值，样本不足 3 天时为 1
+func (m *Model) ratio(obs []Observation, match func(o Observation) bool, withHoliday bool) float64 {
+	var sum, n float64
+	for _, o := range obs {
+		if !match(o) {
+			continue
+		}
+		base := m.Level * m.Weekday[o.Date.Weekday()]
+		if withHoliday && m.isHoliday(o.Date) {
+			base *= m.Holiday
+		}
+		if base <= 0 {
+			continue
+		}
+		sum += o.Count / base
+		n++
+	}
+	if n < 3 {
+		return 1
+	}
+	return sum / n
+}
+
+func (m *Model) isHoliday(date time.Time) bool {
+	return m.options.Holiday != nil && m.options.Holiday(date)
+}
+
+func (m *Model) isRainy(date time.Time) bool {
+	if m.options.Weather == nil {
+		return false
+	}
+	v, ok := m.options.Weather[date.Format(dateLayout)]
+	return ok && v >= rainThreshold
+}
+
+func (m *Model) expected(date time.Time) float64 {
+	v := m.Level * m.Weekday[date.Weekday()]
+	if m.isHoliday(date) {
+		v *= m.Holiday
+	}
+	if m.isRainy(date) {
+		v *= m.Rain
+	}
+	return v
+}
+
+// Predict 预测最后一个历史日期之后 days 天，区间随预测步长逐步放宽
+func (m *Model) Predict(days int) []*Point {
+	points := make([]*Point, 0, days)
+	for h := 1; h <= days; h++ {
+		date := m.Last.AddDate(0, 0, h)
+		v := m.expected(date)
+		spread := m.options.Z * m.Sigma * math.Sqrt(1+float64(h)/28)
+		points = append(points, &Point{
+			Date:    date,
+			Value:   v,
+			Lower:   v * math.Exp(-spread),
+			Upper:   v * math.Exp(spread),
+			Holiday: m.isHoliday(date),
+		})
+	}
+	return points
+}
+
+// Forecast 拟合并预测 days 天，days 取值 14-30
+func Forecast(history []Observation, days int, opt ...Option) ([]*Point, error) {
+	if days < 14 || days > 30 {
+		return nil, fmt.Errorf("forecast horizon must be 14-30 days, got %d", days)
+	}
+	m, err := Fit(history, opt...)
+	if err != nil {
+		return nil, err
+	}
+	return m.Predict(days), nil
+}
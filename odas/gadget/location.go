@@ -0,0 +1,251 @@
+package gadget
+
+import (
+	_ "embed"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/piaofutong/odas-sdk/odas/division"
+)
+
+//go:embed locations.csv
+var builtinLocations string
+
+var (
+	ErrLocationNotFound  = errors.New("weather: location not found")
+	ErrLocationAmbiguous = errors.New("weather: location ambiguous")
+)
+
+// Location 行政区划与天气位置代码，City、District 为空表示省级或市级记录。
+// Level 为记录所在层级，Resolve 区县找不到退回城市时可据此判断实际匹配到的层级
+type Location struct {
+	Code     string         `json:"code"`
+	Province string         `json:"province"`
+	City     string         `json:"city"`
+	District string         `json:"district"`
+	Level    division.Level `json:"level"`
+}
+
+// NormalizeAdminName 统一行政区划名称写法，规则同 division.Normalize
+func NormalizeAdminName(name string) string {
+	return division.Normalize(name)
+}
+
+type locationKey struct {
+	province string
+	city     string
+	district string
+}
+
+// LocationResolver 按省市区名称或景区 sid 查找天气位置代码
+type LocationResolver struct {
+	mutex     sync.RWMutex
+	locations map[locationKey]*Location
+	sids      map[int]*Location
+}
+
+func NewLocationResolver() *LocationResolver {
+	return &LocationResolver{
+		locations: make(map[locationKey]*Location),
+		sids:      make(map[int]*Location),
+	}
+}
+
+var (
+	defaultLocationsOnce sync.Once
+	defaultLocations     *LocationResolver
+)
+
+// DefaultLocations 内置行政区划数据的共享解析器
+func DefaultLocations() *LocationResolver {
+	defaultLocationsOnce.Do(func() {
+		defaultLocations = NewLocationResolver()
+		if err := defaultLocations.Load(strings.NewReader(builtinLocations)); err != nil {
+			panic(err)
+		}
+	})
+	return defaultLocations
+}
+
+func keyOf(province, city, district string) locationKey {
+	return locationKey{NormalizeAdminName(province), NormalizeAdminName(city), NormalizeAdminName(district)}
+}
+
+// Register 登记或覆盖位置代码，Level 按记录中最细的非空名称设置
+func (r *LocationResolver) Register(loc *Location) {
+	key := keyOf(loc.Province, loc.City, loc.District)
+	switch {
+	case key.district != "":
+		loc.Level = division.LevelDistrict
+	case key.city != "":
+		loc.Level = division.LevelCity
+	default:
+		loc.Level = division.LevelProvince
+	}
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.locations[key] = loc
+}
+
+// Load 从 CSV 读取位置代码，首行为表头 code,province,city,district，格式同内置数据。
+// 内置数据的省、市名称与 division 包的行政区划数据一致，使用规范全称；兵团县级市及新北、基隆
+// 等没有独立站点的城市使用所在地区或相邻城市的站点代码
+func (r *LocationResolver) Load(reader io.Reader) error {
+	records, err := csv.NewReader(reader).ReadAll()
+	if err != nil {
+		return err
+	}
+	for i, record := range records {
+		if i == 0 && record[0] == "code" {
+			continue
+		}
+		if len(record) != 4 {
+			return fmt.Errorf("locations line %d: want 4 fields, got %d", i+1, len(record))
+		}
+		r.Register(&Location{Code: record[0], Province: record[1], City: record[2], District: record[3]})
+	}
+	return nil
+}
+
+// Resolve 按名称查找位置代码，名称可带或不带 "省"、"市" 等后缀。
+// 区县找不到时退回所在城市，返回记录的 Level 为 division.LevelCity；城市找不到时返回 ErrLocationNotFound，
+// 不退回省会。城市为空时返回省级记录；省份为空时按城市名在全国范围查找
+func (r *LocationResolver) Resolve(province, city, district string) (*Location, error) {
+	key := keyOf(province, city, district)
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	if key.province == "" {
+		return r.resolveWithoutProvince(key)
+	}
+	keys := []locationKey{key}
+	if key.district != "" {
+		keys = append(keys, locationKey{key.province, key.city, ""})
+	}
+	for _, k := range keys {
+		if k.city == "" && k.district != "" {
+			continue
+		}
+		if loc, ok := r.locations[k]; ok {
+			return loc, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s %s %s", ErrLocationNotFound, province, city, district)
+}
+
+// resolveWithoutProvince 仅有城市(及区县)名称，如 portrait.CityRankResponse。
+// 城市所属省份按 division.Default() 的行政区划查找，与 geo 包共用同一份区划数据；
+// 区划中没有的城市(如通过 Load 补充的位置)再按已登记的位置查找
+func (r *LocationResolver) resolveWithoutProvince(key locationKey) (*Location, error) {
+	if key.city == "" {
+		return nil, fmt.Errorf("%w: empty name", ErrLocationNotFound)
+	}
+	match, err := division.Default().City("", key.city)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrLocationAmbiguous, key.city)
+	}
+	if match != nil {
+		province := NormalizeAdminName(match.Parent.Name)
+		for _, k := range []locationKey{{province, key.city, key.district}, {province, key.city, ""}} {
+			if loc, ok := r.locations[k]; ok {
+				return loc, nil
+			}
+		}
+	}
+	var exact, city []*Location
+	for k, loc := range r.locations {
+		if k.city != key.city {
+			continue
+		}
+		if key.district != "" && k.district == key.district {
+			exact = append(exact, loc)
+		}
+		if k.district == "" {
+			city = append(city, loc)
+		}
+	}
+	for _, matches := range [][]*Location{exact, city} {
+		switch len(matches) {
+		case 0:
+			continue
+		case 1:
+			return matches[0], nil
+		}
+		return nil, fmt.Errorf("%w: %s", ErrLocationAmbiguous, key.city)
+	}
+	return nil, fmt.Errorf("%w: %s %s", ErrLocationNotFound, key.city, key.district)
+}
+
+// MapSid 将景区 sid 绑定到行政区划，返回解析到的位置
+func (r *LocationResolver) MapSid(sid int, province, city, district string) (*Location, error) {
+	loc, err := r.Resolve(province, city, district)
+	if err != nil {
+		return nil, err
+	}
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.sids[sid] = loc
+	return loc, nil
+}
+
+// SidLocation 景区与行政区划的对应，Code 不为空时直接使用，不再按名称解析
+type SidLocation struct {
+	Sid      int    `json:"sid"`
+	Code     string `json:"code,omitempty"`
+	Province string `json:"province,omitempty"`
+	City     string `json:"city,omitempty"`
+	District string `json:"district,omitempty"`
+}
+
+// LoadSids 从 JSON 数组读取景区对应关系：
+// [{"sid":1001,"province":"浙江省","city":"杭州市","district":"西湖区"},{"sid":1002,"code":"101010100"}]
+func (r *LocationResolver) LoadSids(reader io.Reader) error {
+	var list []SidLocation
+	if err := json.NewDecoder(reader).Decode(&list); err != nil {
+		return err
+	}
+	for _, s := range list {
+		if s.Code != "" {
+			r.mutex.Lock()
+			r.sids[s.Sid] = &Location{Code: s.Code, Province: s.Province, City: s.City, District: s.District}
+			r.mutex.Unlock()
+			continue
+		}
+		if _, err := r.MapSid(s.Sid, s.Province, s.City, s.District); err != nil {
+			return fmt.Errorf("sid %d: %w", s.Sid, err)
+		}
+	}
+	return nil
+}
+
+// BySid 查找景区的位置代码
+func (r *LocationResolver) BySid(sid int) (*Location, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	if loc, ok := r.sids[sid]; ok {
+		return loc, nil
+	}
+	return nil, fmt.Errorf("%w: sid %d", ErrLocationNotFound, sid)
+}
+
+// WeatherBySid 生成景区所在地的天气查询
+func (r *LocationResolver) WeatherBySid(sid int, opts ...WeatherOption) (*Weather, error) {
+	loc, err := r.BySid(sid)
+	if err != nil {
+		return nil, err
+	}
+	return NewWeather(loc.Code, opts...), nil
+}
+
+// WeatherByName 生成某地的天气查询
+func (r *LocationResolver) WeatherByName(province, city, district string, opts ...WeatherOption) (*Weather, error) {
+	loc, err := r.Resolve(province, city, district)
+	if err != nil {
+		return nil, err
+	}
+	return NewWeather(loc.Code, opts...), nil
+}
@@ -60,11 +60,14 @@ func (o *Weather) Api() string {
 	v := url.Values{}
 	if o.Options.enableForecast {
 		v.Add("forecast", "1")
-	} else if o.Options.enableAQI {
+	}
+	if o.Options.enableAQI {
 		v.Add("aqi", "1")
-	} else if o.Options.enableWarnings {
+	}
+	if o.Options.enableWarnings {
 		v.Add("warnings", "1")
-	} else if o.Options.enableIndex {
+	}
+	if o.Options.enableIndex {
 		v.Add("index", "1")
 	}
 	if len(v) > 0 {
@@ -102,6 +105,7 @@ type WeatherResponse struct {
 	Forecast []WeatherForecast `json:"forecast,omitempty"`
 	Index    []WeatherIndex    `json:"index,omitempty"`
 	AQI      WeatherAQI        `json:"aqi,omitempty"`
+	Warnings []WeatherWarning  `json:"warnings,omitempty"`
 }
 
 type WeatherNow struct {
@@ -174,3 +178,23 @@ type WeatherAQI struct {
 	Co       string `json:"co"`
 	O3       string `json:"o3"`
 }
+
+// WeatherWarning 气象灾害预警
+type WeatherWarning struct {
+	Id            string `json:"id"`
+	Sender        string `json:"sender"`
+	PubTime       string `json:"pubTime"`
+	Title         string `json:"title"`
+	StartTime     string `json:"startTime"`
+	EndTime       string `json:"endTime"`
+	Status        string `json:"status"`
+	Level         string `json:"level"`
+	Severity      string `json:"severity"`
+	SeverityColor string `json:"severityColor"`
+	Type          string `json:"type"`
+	TypeName      string `json:"typeName"`
+	Urgency       string `json:"urgency"`
+	Certainty     string `json:"certainty"`
+	Text          string `json:"text"`
+	Related       string `json:"related"`
+}
@@ -0,0 +1,315 @@
+package gadget
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/piaofutong/odas-sdk/odas"
+)
+
+// ErrNoValue 字段为空，数据源未提供该值
+var ErrNoValue = errors.New("weather: no value")
+
+// units 可能附带在数值后的单位，较长的在前
+var units = []string{"km/h", "μg/m³", "mg/m³", "hPa", "mm", "km", "℃", "°C", "°", "%"}
+
+// ParseFloat 解析天气数值，只去掉常见单位后缀，不做单位换算，如 "25"、"25℃"、"60%"、"1.2mm"。
+// 需要换算到标准单位时使用 ParseQuantity
+func ParseFloat(s string) (float64, error) {
+	s = strings.TrimSpace(s)
+	for _, u := range units {
+		if strings.HasSuffix(s, u) {
+			s = strings.TrimSpace(strings.TrimSuffix(s, u))
+			break
+		}
+	}
+	return parseNumber(s)
+}
+
+func parseNumber(s string) (float64, error) {
+	if s == "" {
+		return 0, ErrNoValue
+	}
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, fmt.Errorf("weather: invalid number %q", s)
+	}
+	return v, nil
+}
+
+// Unit 天气数值的标准单位
+type Unit string
+
+const (
+	Celsius     Unit = "°C"
+	KmPerHour   Unit = "km/h"
+	Millimetre  Unit = "mm"
+	Kilometre   Unit = "km"
+	Hectopascal Unit = "hPa"
+	Percent     Unit = "%"
+	Degree      Unit = "°"
+	MicrogramM3 Unit = "μg/m³"
+	MilligramM3 Unit = "mg/m³"
+)
+
+type conversion struct {
+	suffix string
+	to     func(v float64) float64
+}
+
+func scale(k float64) func(float64) float64 { return func(v float64) float64 { return v * k } }
+
+var identity = scale(1)
+
+// conversions 各标准单位可接受的单位后缀及换算，按后缀长度从长到短匹配
+var conversions = map[Unit][]conversion{
+	Celsius: {
+		{"℃", identity}, {"°C", identity}, {"°", identity},
+		{"℉", fahrenheit}, {"°F", fahrenheit},
+		{"K", func(v float64) float64 { return v - 273.15 }},
+	},
+	KmPerHour: {
+		{"km/h", identity}, {"kmh", identity}, {"公里/小时", identity},
+		{"m/s", scale(3.6)}, {"米/秒", scale(3.6)},
+		{"mph", scale(1.609344)}, {"kn", scale(1.852)}, {"kt", scale(1.852)},
+	},
+	Millimetre: {
+		{"mm", identity}, {"毫米", identity}, {"cm", scale(10)}, {"in", scale(25.4)},
+	},
+	Kilometre: {
+		{"km", identity}, {"公里", identity}, {"mi", scale(1.609344)}, {"m", scale(0.001)}, {"米", scale(0.001)},
+	},
+	Hectopascal: {
+		{"hPa", identity}, {"mbar", identity}, {"mb", identity}, {"百帕", identity},
+		{"kPa", scale(10)}, {"inHg", scale(33.8639)}, {"Pa", scale(0.01)},
+	},
+	Percent: {{"%", identity}},
+	Degree:  {{"°", identity}},
+	MicrogramM3: {
+		{"μg/m³", identity}, {"µg/m³", identity}, {"ug/m3", identity},
+		{"mg/m³", scale(1000)}, {"mg/m3", scale(1000)},
+	},
+	MilligramM3: {
+		{"mg/m³", identity}, {"mg/m3", identity},
+		{"μg/m³", scale(0.001)}, {"µg/m³", scale(0.001)}, {"ug/m3", scale(0.001)},
+	},
+}
+
+func fahrenheit(v float64) float64 { return (v - 32) * 5 / 9 }
+
+func init() {
+	for _, cs := range conversions {
+		sort.SliceStable(cs, func(i, j int) bool { return len(cs[i].suffix) > len(cs[j].suffix) })
+	}
+}
+
+// ParseQuantity 解析天气数值并换算到标准单位 unit，如 "5m/s" 按 KmPerHour 为 18、"77°F" 按 Celsius 为 25；
+// 不带单位时视为已是标准单位，无法识别的单位返回错误
+func ParseQuantity(s string, unit Unit) (float64, error) {
+	s = strings.TrimSpace(s)
+	to := identity
+	for _, c := range conversions[unit] {
+		if strings.HasSuffix(s, c.suffix) {
+			s, to = strings.TrimSpace(strings.TrimSuffix(s, c.suffix)), c.to
+			break
+		}
+	}
+	v, err := parseNumber(s)
+	if err != nil {
+		return 0, err
+	}
+	return to(v), nil
+}
+
+// ParseInt 解析整数类天气数值，小数按四舍五入取整
+func ParseInt(s string) (int, error) {
+	v, err := ParseFloat(s)
+	if err != nil {
+		return 0, err
+	}
+	return int(math.Round(v)), nil
+}
+
+// ParseTime 解析 "2006-01-02T15:04+08:00" 或 RFC3339 格式的时间
+func ParseTime(s string) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return time.Time{}, ErrNoValue
+	}
+	for _, layout := range []string{"2006-01-02T15:04Z07:00", time.RFC3339} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("weather: invalid time %q", s)
+}
+
+// ParseDate 解析 "2006-01-02" 格式的日期，时区为 Asia/Shanghai
+func ParseDate(s string) (time.Time, error) {
+	if strings.TrimSpace(s) == "" {
+		return time.Time{}, ErrNoValue
+	}
+	t, err := time.ParseInLocation("2006-01-02", strings.TrimSpace(s), odas.Shanghai)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("weather: invalid date %q", s)
+	}
+	return t, nil
+}
+
+// parseClock 将 "06:58" 落在 date 当天
+func parseClock(date, clock string) (time.Time, error) {
+	d, err := ParseDate(date)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if strings.TrimSpace(clock) == "" {
+		return time.Time{}, ErrNoValue
+	}
+	c, err := time.Parse("15:04", strings.TrimSpace(clock))
+	if err != nil {
+		return time.Time{}, fmt.Errorf("weather: invalid clock %q", clock)
+	}
+	return d.Add(time.Duration(c.Hour())*time.Hour + time.Duration(c.Minute())*time.Minute), nil
+}
+
+// ObservedAt 观测时间
+func (n WeatherNow) ObservedAt() (time.Time, error) { return ParseTime(n.ObsTime) }
+
+// TempC 温度，摄氏度
+func (n WeatherNow) TempC() (float64, error) { return ParseQuantity(n.Temp, Celsius) }
+
+// FeelsLikeC 体感温度，摄氏度
+func (n WeatherNow) FeelsLikeC() (float64, error) { return ParseQuantity(n.FeelsLike, Celsius) }
+
+// DewC 露点温度，摄氏度
+func (n WeatherNow) DewC() (float64, error) { return ParseQuantity(n.Dew, Celsius) }
+
+// WindDegree 风向 360 角度
+func (n WeatherNow) WindDegree() (int, error) {
+	v, err := ParseQuantity(n.Wind360, Degree)
+	return int(math.Round(v)), err
+}
+
+// WindSpeedKmh 风速，公里/小时
+func (n WeatherNow) WindSpeedKmh() (float64, error) { return ParseQuantity(n.WindSpeed, KmPerHour) }
+
+// HumidityPct 相对湿度，百分比
+func (n WeatherNow) HumidityPct() (float64, error) { return ParseQuantity(n.Humidity, Percent) }
+
+// PrecipMm 过去 1 小时降水量，毫米
+func (n WeatherNow) PrecipMm() (float64, error) { return ParseQuantity(n.Precip, Millimetre) }
+
+// PressureHpa 大气压强，百帕
+func (n WeatherNow) PressureHpa() (float64, error) { return ParseQuantity(n.Pressure, Hectopascal) }
+
+// VisKm 能见度，公里
+func (n WeatherNow) VisKm() (float64, error) { return ParseQuantity(n.Vis, Kilometre) }
+
+// CloudPct 云量，百分比
+func (n WeatherNow) CloudPct() (float64, error) { return ParseQuantity(n.Cloud, Percent) }
+
+// Date 预报日期
+func (f WeatherForecast) Date() (time.Time, error) { return ParseDate(f.FxDate) }
+
+// SunriseAt 日出时间，极昼极夜时返回 ErrNoValue
+func (f WeatherForecast) SunriseAt() (time.Time, error) { return parseClock(f.FxDate, f.Sunrise) }
+
+// SunsetAt 日落时间
+func (f WeatherForecast) SunsetAt() (time.Time, error) { return parseClock(f.FxDate, f.Sunset) }
+
+// TempMaxC 最高温度，摄氏度
+func (f WeatherForecast) TempMaxC() (float64, error) { return ParseQuantity(f.TempMax, Celsius) }
+
+// TempMinC 最低温度，摄氏度
+func (f WeatherForecast) TempMinC() (float64, error) { return ParseQuantity(f.TempMin, Celsius) }
+
+// WindSpeedDayKmh 白天风速，公里/小时
+func (f WeatherForecast) WindSpeedDayKmh() (float64, error) {
+	return ParseQuantity(f.WindSpeedDay, KmPerHour)
+}
+
+// WindSpeedNightKmh 夜间风速，公里/小时
+func (f WeatherForecast) WindSpeedNightKmh() (float64, error) {
+	return ParseQuantity(f.WindSpeedNight, KmPerHour)
+}
+
+// HumidityPct 相对湿度，百分比
+func (f WeatherForecast) HumidityPct() (float64, error) { return ParseQuantity(f.Humidity, Percent) }
+
+// PrecipMm 当天总降水量，毫米
+func (f WeatherForecast) PrecipMm() (float64, error) { return ParseQuantity(f.Precip, Millimetre) }
+
+// PressureHpa 大气压强，百帕
+func (f WeatherForecast) PressureHpa() (float64, error) {
+	return ParseQuantity(f.Pressure, Hectopascal)
+}
+
+// VisKm 能见度，公里
+func (f WeatherForecast) VisKm() (float64, error) { return ParseQuantity(f.Vis, Kilometre) }
+
+// CloudPct 云量，百分比
+func (f WeatherForecast) CloudPct() (float64, error) { return ParseQuantity(f.Cloud, Percent) }
+
+// UV 紫外线强度指数
+func (f WeatherForecast) UV() (int, error) { return ParseInt(f.UvIndex) }
+
+// Day 指数日期
+func (i WeatherIndex) Day() (time.Time, error) { return ParseDate(i.Date) }
+
+// LevelValue 指数等级
+func (i WeatherIndex) LevelValue() (int, error) { return ParseInt(i.Level) }
+
+// PublishedAt 发布时间
+func (a WeatherAQI) PublishedAt() (time.Time, error) { return ParseTime(a.PubTime) }
+
+// AQIValue 空气质量指数
+func (a WeatherAQI) AQIValue() (int, error) { return ParseInt(a.Aqi) }
+
+// LevelValue 空气质量等级
+func (a WeatherAQI) LevelValue() (int, error) { return ParseInt(a.Level) }
+
+// Pm10Ugm3 PM10 浓度，微克/立方米
+func (a WeatherAQI) Pm10Ugm3() (float64, error) { return ParseQuantity(a.Pm10, MicrogramM3) }
+
+// Pm2P5Ugm3 PM2.5 浓度，微克/立方米
+func (a WeatherAQI) Pm2P5Ugm3() (float64, error) { return ParseQuantity(a.Pm2P5, MicrogramM3) }
+
+// No2Ugm3 二氧化氮浓度，微克/立方米
+func (a WeatherAQI) No2Ugm3() (float64, error) { return ParseQuantity(a.No2, MicrogramM3) }
+
+// So2Ugm3 二氧化硫浓度，微克/立方米
+func (a WeatherAQI) So2Ugm3() (float64, error) { return ParseQuantity(a.So2, MicrogramM3) }
+
+// CoMgm3 一氧化碳浓度，毫克/立方米
+func (a WeatherAQI) CoMgm3() (float64, error) { return ParseQuantity(a.Co, MilligramM3) }
+
+// O3Ugm3 臭氧浓度，微克/立方米
+func (a WeatherAQI) O3Ugm3() (float64, error) { return ParseQuantity(a.O3, MicrogramM3) }
+
+// PublishedAt 预警发布时间
+func (w WeatherWarning) PublishedAt() (time.Time, error) { return ParseTime(w.PubTime) }
+
+// StartAt 预警开始时间
+func (w WeatherWarning) StartAt() (time.Time, error) { return ParseTime(w.StartTime) }
+
+// EndAt 预警结束时间
+func (w WeatherWarning) EndAt() (time.Time, error) { return ParseTime(w.EndTime) }
+
+// Active 预警在 t 时刻是否生效，未给出起止时间时只看状态
+func (w WeatherWarning) Active(t time.Time) bool {
+	if w.Status == "cancel" {
+		return false
+	}
+	if start, err := w.StartAt(); err == nil && t.Before(start) {
+		return false
+	}
+	if end, err := w.EndAt(); err == nil && t.After(end) {
+		return false
+	}
+	return true
+}
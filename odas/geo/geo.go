@@ -0,0 +1,46 @@
+package geo
+
+import "github.com/piaofutong/odas-sdk/odas/division"
+
+// 行政区划数据与名称规范化在 division 包中，不依赖其他 odas 包，gadget 等包可直接使用；
+// 这里保留别名，geo 的汇总与导出沿用同一份区划数据
+
+// China 国家节点名称
+const China = division.China
+
+var ErrAmbiguous = division.ErrAmbiguous
+
+type Level = division.Level
+
+const (
+	LevelTotal    = division.LevelTotal
+	LevelCountry  = division.LevelCountry
+	LevelProvince = division.LevelProvince
+	LevelCity     = division.LevelCity
+	LevelDistrict = division.LevelDistrict
+)
+
+// Division 行政区划节点，同 division.Division
+type Division = division.Division
+
+// Tree 国内省、市两级行政区划，同 division.Tree
+type Tree = division.Tree
+
+func NewTree() *Tree {
+	return division.NewTree()
+}
+
+// Default 内置行政区划数据的共享区划树，与 division.Default 为同一实例
+func Default() *Tree {
+	return division.Default()
+}
+
+// Normalize 统一行政区划名称的写法，规则见 division.Normalize
+func Normalize(name string) string {
+	return division.Normalize(name)
+}
+
+// IsChina 国家名称是否指中国，空值按中国处理
+func IsChina(country string) bool {
+	return division.IsChina(country)
+}
@@ -0,0 +1,314 @@
+package geo
+
+import (
+	_ "embed"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/piaofutong/odas-sdk/odas/portrait"
+	"github.com/piaofutong/odas-sdk/odas/tourist"
+)
+
+//go:embed shapes.csv
+var builtinShapes string
+
+// FeatureCollection GeoJSON 要素集合
+type FeatureCollection struct {
+	Type     string     `json:"type"`
+	Features []*Feature `json:"features"`
+}
+
+type Feature struct {
+	Type       string         `json:"type"`
+	Geometry   *Geometry      `json:"geometry"`
+	Properties map[string]any `json:"properties"`
+}
+
+// Geometry GeoJSON 几何对象，Coordinates 原样保存，支持 Polygon、MultiPolygon、Point 等
+type Geometry struct {
+	Type        string          `json:"type"`
+	Coordinates json.RawMessage `json:"coordinates"`
+}
+
+// Shape 区划的中心点与边界，Geometry 为空表示只有中心点
+type Shape struct {
+	Name     string
+	Level    Level
+	Center   [2]float64 // 经度, 纬度
+	Geometry *Geometry
+}
+
+// Shapes 按层级保存区划形状。
+// 内置数据覆盖全部省、市，中心点为省会及市政府位置，边界为经纬度外接矩形(精度约 0.1°，相邻矩形会重叠)，
+// 可直接用于 Choropleth 概览着色；需要准确边界时通过 LoadGeoJSON 载入覆盖
+type Shapes struct {
+	mutex  sync.RWMutex
+	shapes map[Level]map[string]*Shape
+}
+
+func NewShapes() *Shapes {
+	return &Shapes{shapes: make(map[Level]map[string]*Shape)}
+}
+
+var (
+	defaultShapesOnce sync.Once
+	defaultShapes     *Shapes
+)
+
+// DefaultShapes 内置省、市中心点与外接矩形数据
+func DefaultShapes() *Shapes {
+	defaultShapesOnce.Do(func() {
+		defaultShapes = NewShapes()
+		if err := defaultShapes.load(strings.NewReader(builtinShapes)); err != nil {
+			panic(err)
+		}
+	})
+	return defaultShapes
+}
+
+// Register 登记或覆盖区划形状
+func (s *Shapes) Register(shape *Shape) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	m, ok := s.shapes[shape.Level]
+	if !ok {
+		m = make(map[string]*Shape)
+		s.shapes[shape.Level] = m
+	}
+	m[Normalize(shape.Name)] = shape
+}
+
+// Lookup 按名称查找区划形状，名称可带或不带后缀
+func (s *Shapes) Lookup(level Level, name string) (*Shape, bool) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	shape, ok := s.shapes[level][Normalize(name)]
+	return shape, ok
+}
+
+var levelNames = map[string]Level{"province": LevelProvince, "city": LevelCity, "district": LevelDistrict}
+
+// load 读取内置 CSV：level,name,lon,lat,minLon,minLat,maxLon,maxLat，外接矩形登记为 Polygon
+func (s *Shapes) load(r io.Reader) error {
+	records, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		return err
+	}
+	for i, record := range records[1:] {
+		if len(record) != 8 {
+			return fmt.Errorf("shapes line %d: want 8 fields, got %d", i+2, len(record))
+		}
+		level, ok := levelNames[record[0]]
+		if !ok {
+			return fmt.Errorf("shapes line %d: unknown level %q", i+2, record[0])
+		}
+		var values [6]float64
+		for j := range values {
+			if values[j], err = strconv.ParseFloat(record[j+2], 64); err != nil {
+				return fmt.Errorf("shapes line %d: %w", i+2, err)
+			}
+		}
+		minLon, minLat, maxLon, maxLat := values[2], values[3], values[4], values[5]
+		coordinates, _ := json.Marshal([][][2]float64{{
+			{minLon, minLat}, {maxLon, minLat}, {maxLon, maxLat}, {minLon, maxLat}, {minLon, minLat},
+		}})
+		s.Register(&Shape{
+			Name:     record[1],
+			Level:    level,
+			Center:   [2]float64{values[0], values[1]},
+			Geometry: &Geometry{Type: "Polygon", Coordinates: coordinates},
+		})
+	}
+	return nil
+}
+
+// LoadGeoJSON 载入某一层级的边界数据，如 DataV 导出的省、市边界。
+// 要素需带 properties.name；中心点取 properties.center 或 properties.centroid，缺失时用边界外接矩形的中心
+func (s *Shapes) LoadGeoJSON(r io.Reader, level Level) error {
+	var fc struct {
+		Features []struct {
+			Geometry   *Geometry `json:"geometry"`
+			Properties struct {
+				Name     string    `json:"name"`
+				Center   []float64 `json:"center"`
+				Centroid []float64 `json:"centroid"`
+			} `json:"properties"`
+		} `json:"features"`
+	}
+	if err := json.NewDecoder(r).Decode(&fc); err != nil {
+		return err
+	}
+	for i, f := range fc.Features {
+		if f.Properties.Name == "" || f.Geometry == nil {
+			continue
+		}
+		shape := &Shape{Name: f.Properties.Name, Level: level, Geometry: f.Geometry}
+		switch {
+		case len(f.Properties.Center) == 2:
+			shape.Center = [2]float64{f.Properties.Center[0], f.Properties.Center[1]}
+		case len(f.Properties.Centroid) == 2:
+			shape.Center = [2]float64{f.Properties.Centroid[0], f.Properties.Centroid[1]}
+		default:
+			center, err := boundsCenter(f.Geometry.Coordinates)
+			if err != nil {
+				return fmt.Errorf("feature %d %s: %w", i, f.Properties.Name, err)
+			}
+			shape.Center = center
+		}
+		s.Register(shape)
+	}
+	return nil
+}
+
+// boundsCenter 任意嵌套坐标数组的外接矩形中心
+func boundsCenter(raw json.RawMessage) ([2]float64, error) {
+	var v any
+	if err := json.Unmarshal(raw, &v); err != nil {
+		return [2]float64{}, err
+	}
+	minLon, minLat := math.Inf(1), math.Inf(1)
+	maxLon, maxLat := math.Inf(-1), math.Inf(-1)
+	var walk func(any)
+	walk = func(v any) {
+		list, ok := v.([]any)
+		if !ok {
+			return
+		}
+		if len(list) >= 2 {
+			lon, okLon := list[0].(float64)
+			lat, okLat := list[1].(float64)
+			if okLon && okLat {
+				minLon, maxLon = math.Min(minLon, lon), math.Max(maxLon, lon)
+				minLat, maxLat = math.Min(minLat, lat), math.Max(maxLat, lat)
+				return
+			}
+		}
+		for _, item := range list {
+			walk(item)
+		}
+	}
+	walk(v)
+	if math.IsInf(minLon, 1) {
+		return [2]float64{}, fmt.Errorf("no coordinates")
+	}
+	return [2]float64{(minLon + maxLon) / 2, (minLat + maxLat) / 2}, nil
+}
+
+// Region 某一区划的客源数据，Compare 为同比/环比变化率，为空表示无对比数据
+type Region struct {
+	Name    string
+	Count   int
+	Rate    float64
+	Compare *float64
+}
+
+func (r Region) properties(shape *Shape) map[string]any {
+	props := map[string]any{
+		"name":  shape.Name,
+		"level": shape.Level.String(),
+		"count": r.Count,
+		"rate":  r.Rate,
+	}
+	if r.Compare != nil {
+		props["compare"] = *r.Compare
+	}
+	return props
+}
+
+// Choropleth 生成带客源属性的区划面要素，没有边界数据的区划名称在 unmatched 中返回
+func (s *Shapes) Choropleth(level Level, regions []Region) (fc *FeatureCollection, unmatched []string) {
+	fc = &FeatureCollection{Type: "FeatureCollection", Features: []*Feature{}}
+	for _, r := range regions {
+		shape, ok := s.Lookup(level, r.Name)
+		if !ok || shape.Geometry == nil {
+			unmatched = append(unmatched, r.Name)
+			continue
+		}
+		fc.Features = append(fc.Features, &Feature{Type: "Feature", Geometry: shape.Geometry, Properties: r.properties(shape)})
+	}
+	return fc, unmatched
+}
+
+// Points 生成区划中心点要素，用于迁徙图、气泡图，没有中心点数据的区划名称在 unmatched 中返回
+func (s *Shapes) Points(level Level, regions []Region) (fc *FeatureCollection, unmatched []string) {
+	fc = &FeatureCollection{Type: "FeatureCollection", Features: []*Feature{}}
+	for _, r := range regions {
+		shape, ok := s.Lookup(level, r.Name)
+		if !ok {
+			unmatched = append(unmatched, r.Name)
+			continue
+		}
+		coordinates, _ := json.Marshal(shape.Center)
+		fc.Features = append(fc.Features, &Feature{
+			Type:       "Feature",
+			Geometry:   &Geometry{Type: "Point", Coordinates: coordinates},
+			Properties: r.properties(shape),
+		})
+	}
+	return fc, unmatched
+}
+
+// ProvinceRegions 省客源排行，不带对比数据
+func ProvinceRegions(list []*portrait.ProvinceRankResponse) []Region {
+	out := make([]Region, 0, len(list))
+	for _, item := range list {
+		out = append(out, Region{Name: item.Province, Count: item.Total, Rate: item.Rate})
+	}
+	return out
+}
+
+// ProvinceCompareRegions 带对比周期查询的省客源排行，Compare 取 compareTotalRate。
+// 未带对比周期时接口的 compareTotalRate 恒为 0，应使用 ProvinceRegions
+func ProvinceCompareRegions(list []*portrait.ProvinceRankResponse) []Region {
+	out := ProvinceRegions(list)
+	for i, item := range list {
+		rate := item.CompareTotalRate
+		out[i].Compare = &rate
+	}
+	return out
+}
+
+// CityRegions 市客源排行，不带对比数据
+func CityRegions(list []*portrait.CityRankResponse) []Region {
+	out := make([]Region, 0, len(list))
+	for _, item := range list {
+		out = append(out, Region{Name: item.City, Count: item.Total, Rate: item.Rate})
+	}
+	return out
+}
+
+// CityCompareRegions 带对比周期查询的市客源排行，同 ProvinceCompareRegions
+func CityCompareRegions(list []*portrait.CityRankResponse) []Region {
+	out := CityRegions(list)
+	for i, item := range list {
+		rate := item.CompareTotalRate
+		out[i].Compare = &rate
+	}
+	return out
+}
+
+// LocalRegions 本地游客分布，省内数据按城市、省外数据按省份
+func LocalRegions(resp *tourist.LocalResponse) (cities, provinces []Region) {
+	for _, item := range resp.Inside {
+		cities = append(cities, Region{Name: item.City, Count: item.Count, Rate: item.Rate})
+	}
+	for _, item := range resp.Outside {
+		provinces = append(provinces, Region{Name: item.Province, Count: item.Count, Rate: item.Rate})
+	}
+	return cities, provinces
+}
+
+// Regions 以汇总结果的子节点生成区划数据，Rate 为占本节点的比例，如 root.Find("中国").Regions() 得到各省
+func (n *Node) Regions() []Region {
+	out := make([]Region, 0, len(n.Children))
+	for _, c := range n.Children {
+		out = append(out, Region{Name: c.Name, Count: c.Count, Rate: c.Share})
+	}
+	return out
+}
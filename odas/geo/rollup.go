@@ -0,0 +1,213 @@
+package geo
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/piaofutong/odas-sdk/odas/portrait"
+	"github.com/piaofutong/odas-sdk/odas/tourist"
+)
+
+// Unknown 无法归属到上级区划的名称统一挂在该节点下
+const Unknown = "未知"
+
+// Node 汇总结果节点。Count 含下级合计，Direct 为只精确到本级、无法分配给下级的数量；
+// Share 为占上级的比例，TotalShare 为占总数的比例
+type Node struct {
+	Name       string  `json:"name"`
+	Level      Level   `json:"level"`
+	Count      int     `json:"count"`
+	Direct     int     `json:"direct,omitempty"`
+	Share      float64 `json:"share"`
+	TotalShare float64 `json:"totalShare"`
+	Children   []*Node `json:"children,omitempty"`
+
+	parent *Node
+	index  map[string]*Node
+}
+
+func (n *Node) child(name string, level Level) *Node {
+	key := Normalize(name)
+	if key == "" {
+		key = name
+	}
+	if c, ok := n.index[key]; ok {
+		return c
+	}
+	c := &Node{Name: name, Level: level, parent: n, index: make(map[string]*Node)}
+	if n.index == nil {
+		n.index = make(map[string]*Node)
+	}
+	n.index[key] = c
+	n.Children = append(n.Children, c)
+	return c
+}
+
+// Find 按路径查找子节点，如 Find("中国", "浙江", "杭州")，名称可带或不带后缀
+func (n *Node) Find(path ...string) *Node {
+	cur := n
+	for _, name := range path {
+		key := Normalize(name)
+		if key == "" {
+			key = name
+		}
+		next, ok := cur.index[key]
+		if !ok {
+			return nil
+		}
+		cur = next
+	}
+	return cur
+}
+
+// Rollup 将不同层级的客源数据逐级汇总到国家、省、市、区县。
+// 同一批游客只应添加一次：省排行与市排行描述的是同一批游客，同时添加会重复计数
+type Rollup struct {
+	tree *Tree
+	root *Node
+}
+
+// NewRollup 使用 tree 解析区划名称，tree 为空时使用 Default()
+func NewRollup(tree *Tree) *Rollup {
+	if tree == nil {
+		tree = Default()
+	}
+	return &Rollup{tree: tree, root: &Node{Name: "合计", Level: LevelTotal, index: make(map[string]*Node)}}
+}
+
+// Add 记录一条客源数据，可只给出部分层级。
+// 国内数据只有城市时按区划树补全省份，无法唯一确定省份的城市挂在 "未知" 省份下；
+// 区划树中没有的城市、区县按原名称挂载
+func (r *Rollup) Add(country, province, city, district string, count int) {
+	if !IsChina(country) {
+		node := r.root.child(strings.TrimSpace(country), LevelCountry)
+		if Normalize(province) != "" {
+			node = node.child(strings.TrimSpace(province), LevelProvince)
+		}
+		node.Direct += count
+		return
+	}
+	node := r.root.child(China, LevelCountry)
+
+	provinceName := strings.TrimSpace(province)
+	cityName := strings.TrimSpace(city)
+	if p, ok := r.tree.Province(province); ok {
+		provinceName = p.Name
+		// 直辖市的城市常为 "市辖区" 等占位名称，直接归到直辖市本身
+		if Normalize(cityName) == "" && len(p.Children) == 1 && Normalize(p.Children[0].Name) == Normalize(p.Name) {
+			cityName = p.Children[0].Name
+		}
+	}
+	if c, err := r.tree.City(province, city); err == nil && c != nil {
+		cityName = c.Name
+		provinceName = c.Parent.Name
+	}
+	if Normalize(provinceName) == "" {
+		if Normalize(cityName) == "" && Normalize(district) == "" {
+			node.Direct += count
+			return
+		}
+		provinceName = Unknown
+	}
+	node = node.child(provinceName, LevelProvince)
+
+	if Normalize(cityName) == "" {
+		if Normalize(district) == "" {
+			node.Direct += count
+			return
+		}
+		cityName = Unknown
+	}
+	node = node.child(cityName, LevelCity)
+
+	if Normalize(district) != "" {
+		node = node.child(strings.TrimSpace(district), LevelDistrict)
+	}
+	node.Direct += count
+}
+
+// AddProvinces 添加省客源排行
+func (r *Rollup) AddProvinces(list []*portrait.ProvinceRankResponse) {
+	for _, item := range list {
+		r.Add("", item.Province, "", "", item.Total)
+	}
+}
+
+// AddCities 添加市客源排行，省份按区划树补全
+func (r *Rollup) AddCities(list []*portrait.CityRankResponse) {
+	for _, item := range list {
+		r.Add("", "", item.City, "", item.Total)
+	}
+}
+
+// AddLocations 添加国家、省份客源排行
+func (r *Rollup) AddLocations(resp *portrait.CountryProvinceLocationRankResponse) {
+	for _, item := range resp.List {
+		r.Add(item.Country, item.ProvinceName, "", "", item.Count)
+	}
+}
+
+// AddLocal 添加本地游客分布：省内按城市、省外按省份。province 为景区所在省份，为空时按城市名称补全
+func (r *Rollup) AddLocal(province string, resp *tourist.LocalByTicketResponse) {
+	for _, item := range resp.Inside {
+		r.Add("", province, item.City, "", item.Count)
+	}
+	for _, item := range resp.Outside {
+		r.Add("", item.Province, "", "", item.Count)
+	}
+}
+
+// AddLocalList 添加带省、市、区县名称的客源明细
+func (r *Rollup) AddLocalList(list []*tourist.LocalByTicketResponseListItem) {
+	for _, item := range list {
+		r.Add("", item.ProvinceName, item.CityName, item.DistrictName, item.Count)
+	}
+}
+
+// Result 计算各级合计与占比，子节点按数量从大到小排序
+func (r *Rollup) Result() *Node {
+	sum(r.root)
+	share(r.root, r.root.Count)
+	return r.root
+}
+
+func sum(n *Node) int {
+	n.Count = n.Direct
+	for _, c := range n.Children {
+		n.Count += sum(c)
+	}
+	sort.SliceStable(n.Children, func(i, j int) bool {
+		return n.Children[i].Count > n.Children[j].Count
+	})
+	return n.Count
+}
+
+func share(n *Node, total int) {
+	if total > 0 {
+		n.TotalShare = float64(n.Count) / float64(total)
+	}
+	if n.parent != nil && n.parent.Count > 0 {
+		n.Share = float64(n.Count) / float64(n.parent.Count)
+	}
+	for _, c := range n.Children {
+		share(c, total)
+	}
+}
+
+// Flatten 返回某一层级的全部节点，按数量从大到小排序，需先调用 Result
+func (n *Node) Flatten(level Level) []*Node {
+	var out []*Node
+	var walk func(*Node)
+	walk = func(c *Node) {
+		if c.Level == level {
+			out = append(out, c)
+			return
+		}
+		for _, child := range c.Children {
+			walk(child)
+		}
+	}
+	walk(n)
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Count > out[j].Count })
+	return out
+}
@@ -3,6 +3,7 @@ package hotel
 import (
 	"fmt"
 	"github.com/piaofutong/odas-sdk/odas"
+	"time"
 )
 
 type RoomOrderDateListReq struct {
@@ -44,3 +45,7 @@ type RmOrderDateListData struct {
 	Date string `json:"date"`
 	RmOrderDateTotal
 }
+
+func (d RmOrderDateListData) Bucket(day time.Time) (odas.Bucket, error) {
+	return odas.ParseBucket(d.Date, day)
+}
@@ -3,6 +3,7 @@ package hotel
 import (
 	"fmt"
 	"github.com/piaofutong/odas-sdk/odas"
+	"time"
 )
 
 type RmSaleReportDateListReq struct {
@@ -46,3 +47,7 @@ type RmSaleReportDateListData struct {
 	Date string `json:"date"`
 	RmSaleReportTotal
 }
+
+func (d RmSaleReportDateListData) Bucket(day time.Time) (odas.Bucket, error) {
+	return odas.ParseBucket(d.Date, day)
+}
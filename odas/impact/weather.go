@@ -0,0 +1,287 @@
+package impact
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/piaofutong/odas-sdk/odas"
+	"github.com/piaofutong/odas-sdk/odas/gadget"
+	"github.com/piaofutong/odas-sdk/odas/report"
+	"github.com/piaofutong/odas-sdk/odas/tourist"
+)
+
+const dateLayout = "2006-01-02"
+
+// minBandDays 档位样本少于该天数时不计入预期客流的调整
+const minBandDays = 2
+
+// DailyWeather 单日天气，AQI 为 0 表示无空气质量数据
+type DailyWeather struct {
+	Date     time.Time
+	PrecipMm float64
+	TempMaxC float64
+	TempMinC float64
+	AQI      int
+	// ObservedAt 所在响应的观测时间，同一日期有多条时保留最新的一条
+	ObservedAt time.Time
+}
+
+// DailyWeatherFrom 取天气响应中的逐日预报；AQI 附加在发布日期当天。
+// 降水或温度为空的日期跳过。每天保存一次响应即可积累天气历史。
+func DailyWeatherFrom(r *gadget.WeatherResponse) ([]*DailyWeather, error) {
+	observed, _ := r.Now.ObservedAt()
+	var aqiDate string
+	aqi, aqiErr := r.AQI.AQIValue()
+	if published, err := r.AQI.PublishedAt(); err == nil && aqiErr == nil {
+		aqiDate = published.In(odas.Shanghai).Format(dateLayout)
+	}
+	out := make([]*DailyWeather, 0, len(r.Forecast))
+	for _, f := range r.Forecast {
+		date, err := f.Date()
+		if err != nil {
+			return nil, err
+		}
+		w := &DailyWeather{Date: date, ObservedAt: observed}
+		if w.PrecipMm, err = f.PrecipMm(); err != nil {
+			if errors.Is(err, gadget.ErrNoValue) {
+				continue
+			}
+			return nil, fmt.Errorf("%s precip: %w", f.FxDate, err)
+		}
+		if w.TempMaxC, err = f.TempMaxC(); err != nil {
+			if errors.Is(err, gadget.ErrNoValue) {
+				continue
+			}
+			return nil, fmt.Errorf("%s tempMax: %w", f.FxDate, err)
+		}
+		if w.TempMinC, err = f.TempMinC(); err != nil {
+			if errors.Is(err, gadget.ErrNoValue) {
+				continue
+			}
+			return nil, fmt.Errorf("%s tempMin: %w", f.FxDate, err)
+		}
+		if f.FxDate == aqiDate {
+			w.AQI = aqi
+		}
+		out = append(out, w)
+	}
+	return out, nil
+}
+
+// Visits 日期(yyyy-mm-dd)到客流
+type Visits map[string]float64
+
+// VisitsFromPassengerFlow 取 DailyPassengerFlowReq 响应中的逐日客流
+func VisitsFromPassengerFlow(r *tourist.PassengerFlowByDateResponse) (Visits, error) {
+	v := make(Visits, len(r.List))
+	for _, item := range r.List {
+		b, err := item.Bucket(time.Time{})
+		if err != nil {
+			return nil, err
+		}
+		v[b.Time.Format(dateLayout)] += float64(item.Count)
+	}
+	return v, nil
+}
+
+// AddVerified 记录某日 VerifiedSummaryReq 的验证票数
+func (v Visits) AddVerified(date string, r *report.VerifiedSummaryResponse) {
+	v[date] += float64(r.VerifiedTicket)
+}
+
+// Band 某一天气档位对客流的影响，Impact 为相对同星期均值的变化比例，如 -0.3 表示少 30%
+type Band struct {
+	Label      string
+	Days       int
+	MeanVisits float64
+	Impact     float64
+}
+
+// factor 档位系数，样本不足时为 1
+func (b *Band) factor() float64 {
+	if b == nil || b.Days < minBandDays {
+		return 1
+	}
+	return 1 + b.Impact
+}
+
+type WeatherReport struct {
+	Days        int
+	Weekday     [7]float64 // 各星期的平均客流，作为无天气影响的基线
+	Precip      []*Band
+	Temperature []*Band
+	AQI         []*Band
+}
+
+func precipBand(mm float64) string {
+	switch {
+	case mm < 0.1:
+		return "无雨"
+	case mm < 10:
+		return "小雨"
+	case mm < 25:
+		return "中雨"
+	}
+	return "大雨"
+}
+
+func temperatureBand(maxC float64) string {
+	switch {
+	case maxC < 10:
+		return "寒冷"
+	case maxC < 25:
+		return "舒适"
+	case maxC < 32:
+		return "温暖"
+	}
+	return "炎热"
+}
+
+// aqiBand 按《环境空气质量指数(AQI)技术规定》分级，无数据时为空
+func aqiBand(aqi int) string {
+	switch {
+	case aqi <= 0:
+		return ""
+	case aqi <= 50:
+		return "优"
+	case aqi <= 100:
+		return "良"
+	case aqi <= 150:
+		return "轻度污染"
+	case aqi <= 200:
+		return "中度污染"
+	case aqi <= 300:
+		return "重度污染"
+	}
+	return "严重污染"
+}
+
+var (
+	precipOrder      = []string{"无雨", "小雨", "中雨", "大雨"}
+	temperatureOrder = []string{"寒冷", "舒适", "温暖", "炎热"}
+	aqiOrder         = []string{"优", "良", "轻度污染", "中度污染", "重度污染", "严重污染"}
+)
+
+// latestByDate 同一日期只保留观测时间最新的一条，观测时间相同时保留靠后的一条，结果按日期升序
+func latestByDate(weather []*DailyWeather) []*DailyWeather {
+	latest := make(map[string]*DailyWeather, len(weather))
+	for _, w := range weather {
+		if w == nil {
+			continue
+		}
+		key := w.Date.Format(dateLayout)
+		if prev, ok := latest[key]; !ok || !w.ObservedAt.Before(prev.ObservedAt) {
+			latest[key] = w
+		}
+	}
+	out := make([]*DailyWeather, 0, len(latest))
+	for _, w := range latest {
+		out = append(out, w)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Date.Before(out[j].Date) })
+	return out
+}
+
+// AnalyzeWeather 按日期关联天气与客流，以同星期均值为基线计算各档位的影响。
+// 多次保存的预报会重复覆盖同一日期，每个日期只取观测时间最新的一条
+func AnalyzeWeather(weather []*DailyWeather, visits Visits) (*WeatherReport, error) {
+	type joined struct {
+		w     *DailyWeather
+		count float64
+	}
+	var rows []joined
+	var sums, counts [7]float64
+	for _, w := range latestByDate(weather) {
+		count, ok := visits[w.Date.Format(dateLayout)]
+		if !ok {
+			continue
+		}
+		rows = append(rows, joined{w, count})
+		sums[w.Date.Weekday()] += count
+		counts[w.Date.Weekday()]++
+	}
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("no dates with both weather and visits")
+	}
+
+	r := &WeatherReport{Days: len(rows)}
+	for d := range r.Weekday {
+		if counts[d] > 0 {
+			r.Weekday[d] = sums[d] / counts[d]
+		}
+	}
+
+	type acc struct{ visits, ratio, n float64 }
+	group := func(label func(w *DailyWeather) string, order []string) []*Band {
+		stats := make(map[string]*acc)
+		for _, row := range rows {
+			l := label(row.w)
+			base := r.Weekday[row.w.Date.Weekday()]
+			if l == "" || base == 0 {
+				continue
+			}
+			s, ok := stats[l]
+			if !ok {
+				s = &acc{}
+				stats[l] = s
+			}
+			s.visits += row.count
+			s.ratio += row.count / base
+			s.n++
+		}
+		var bands []*Band
+		for _, l := range order {
+			if s, ok := stats[l]; ok {
+				bands = append(bands, &Band{Label: l, Days: int(s.n), MeanVisits: s.visits / s.n, Impact: s.ratio/s.n - 1})
+			}
+		}
+		return bands
+	}
+	r.Precip = group(func(w *DailyWeather) string { return precipBand(w.PrecipMm) }, precipOrder)
+	r.Temperature = group(func(w *DailyWeather) string { return temperatureBand(w.TempMaxC) }, temperatureOrder)
+	r.AQI = group(func(w *DailyWeather) string { return aqiBand(w.AQI) }, aqiOrder)
+	return r, nil
+}
+
+func findBand(bands []*Band, label string) *Band {
+	for _, b := range bands {
+		if b.Label == label {
+			return b
+		}
+	}
+	return nil
+}
+
+// Expected 按星期基线与降水、温度、AQI 档位系数估算的天气调整后预期客流。
+// 各因素按相互独立相乘，降水与低温同时出现时可能略微高估影响。
+func (r *WeatherReport) Expected(w *DailyWeather) float64 {
+	v := r.Weekday[w.Date.Weekday()]
+	v *= findBand(r.Precip, precipBand(w.PrecipMm)).factor()
+	v *= findBand(r.Temperature, temperatureBand(w.TempMaxC)).factor()
+	if l := aqiBand(w.AQI); l != "" {
+		v *= findBand(r.AQI, l).factor()
+	}
+	return v
+}
+
+type Expectation struct {
+	Weather  *DailyWeather
+	Baseline float64
+	Expected float64
+}
+
+// ExpectedFromForecast 对天气预报中的每一天估算预期客流
+func (r *WeatherReport) ExpectedFromForecast(resp *gadget.WeatherResponse) ([]*Expectation, error) {
+	days, err := DailyWeatherFrom(resp)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(days, func(i, j int) bool { return days[i].Date.Before(days[j].Date) })
+	out := make([]*Expectation, 0, len(days))
+	for _, w := range days {
+		out = append(out, &Expectation{Weather: w, Baseline: r.Weekday[w.Date.Weekday()], Expected: r.Expected(w)})
+	}
+	return out, nil
+}
@@ -10,6 +10,11 @@ type IRequest interface {
 	AuthRequired() bool
 }
 
+// IValidator 请求可选实现的校验，IAM.Do 在发起请求前调用，返回错误时不发起请求
+type IValidator interface {
+	Validate() error
+}
+
 type Response struct {
 	Code   int             `json:"code"`
 	Msg    string          `json:"msg"`
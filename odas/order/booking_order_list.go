@@ -3,6 +3,7 @@ package order
 import (
 	"fmt"
 	"github.com/piaofutong/odas-sdk/odas"
+	"time"
 )
 
 type BookingOrderListReq struct {
@@ -54,3 +55,7 @@ type BookingOrderListDetail struct {
 	Time int `json:"time"`
 	BookingOrderTotal
 }
+
+func (d BookingOrderListDetail) Bucket(day time.Time) (odas.Bucket, error) {
+	return odas.ParseBucketInt(d.Time, day)
+}
@@ -3,6 +3,7 @@ package order
 import (
 	"fmt"
 	"github.com/piaofutong/odas-sdk/odas"
+	"time"
 )
 
 type BookingTeamOrderReq struct {
@@ -19,6 +20,12 @@ func NewBookingTeamOrderReq(req *odas.Req, dateRangeCompareReq *odas.DateRangeCo
 
 func (r *BookingTeamOrderReq) Api() string {
 	params := r.Req.Params()
+	if r.CompareStart != "" {
+		params.Add("compareStart", r.CompareStart)
+	}
+	if r.CompareEnd != "" {
+		params.Add("compareEnd", r.CompareEnd)
+	}
 	return fmt.Sprintf("/v4/order/booking/teamOrder?%s", params.Encode())
 }
 
@@ -45,14 +52,26 @@ type TeamTrend struct {
 	CompareTeam float64 `json:"compareTeam"`
 }
 
+func (t TeamTrend) Bucket(day time.Time) (odas.Bucket, error) {
+	return odas.ParseBucketInt(t.Time, day)
+}
+
 type TeamTicketTrend struct {
 	Time          int     `json:"time"`
 	Ticket        *int    `json:"ticket"`
 	CompareTicket float64 `json:"compareTicket"`
 }
 
+func (t TeamTicketTrend) Bucket(day time.Time) (odas.Bucket, error) {
+	return odas.ParseBucketInt(t.Time, day)
+}
+
 type TeamAmountTrend struct {
 	Time          int     `json:"time"`
 	Amount        *int    `json:"amount"`
 	CompareAmount float64 `json:"compareAmount"`
 }
+
+func (t TeamAmountTrend) Bucket(day time.Time) (odas.Bucket, error) {
+	return odas.ParseBucketInt(t.Time, day)
+}
@@ -2,6 +2,7 @@ package order
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/piaofutong/odas-sdk/odas"
 )
@@ -41,3 +42,7 @@ type AgeGenderCountListItem struct {
 	FemaleAge61plus int `json:"femaleAge61plus"`
 	Time            int `json:"time"`
 }
+
+func (a AgeGenderCountListItem) Bucket(day time.Time) (odas.Bucket, error) {
+	return odas.ParseBucketInt(a.Time, day)
+}
@@ -2,6 +2,7 @@ package order
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/piaofutong/odas-sdk/odas"
 )
@@ -33,6 +34,10 @@ type PreBookingCountryProvinceDistListItem struct {
 	Time      int                                           `json:"time"`
 }
 
+func (p PreBookingCountryProvinceDistListItem) Bucket(day time.Time) (odas.Bucket, error) {
+	return odas.ParseBucketInt(p.Time, day)
+}
+
 type PreBookingCountryProvinceDistResponse struct {
 	List []*PreBookingCountryProvinceDistListItem `json:"list"`
 }
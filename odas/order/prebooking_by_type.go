@@ -29,6 +29,7 @@ func (p PreBookingByTypeReq) Api() string {
 		params.Add("orderType", strconv.Itoa(p.Options.OrderType))
 	}
 
+	// 服务端按类型查询的独立路径尚未确认，暂与 PreBookingSummaryReq 共用接口，以 orderType 区分
 	return fmt.Sprintf("/v4/order/preBookingSummary?%s", params.Encode())
 }
 
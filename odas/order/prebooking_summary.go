@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"github.com/piaofutong/odas-sdk/odas"
 	"strconv"
+	"time"
 )
 
 type PreBookingSummaryReq struct {
@@ -46,3 +47,7 @@ type PreBookingSummaryDateList struct {
 	Time string `json:"time"`
 	PreBookingTotal
 }
+
+func (p PreBookingSummaryDateList) Bucket(day time.Time) (odas.Bucket, error) {
+	return odas.ParseBucket(p.Time, day)
+}
@@ -1,9 +1,12 @@
 package order
 
 import (
+	"encoding/json"
 	"fmt"
 	"github.com/piaofutong/odas-sdk/odas"
+	"math"
 	"strconv"
+	"strings"
 )
 
 type SummaryOptions struct {
@@ -42,41 +45,98 @@ func (r *Summary) Api() string {
 	return fmt.Sprintf("/v4/order/summary?%s", params.Encode())
 }
 
+// SummaryResponse 订单汇总，每组指标分票数和金额
 type SummaryResponse struct {
-	OrderTicket        int      `json:"orderTicket"`
-	MomOrderTicket     *float64 `json:"momOrderTicket"`
-	YoyOrderTicket     *float64 `json:"yoyOrderTicket"`
-	VerifyTicket       int      `json:"verifyTicket"`
-	MomVerifyTicket    *float64 `json:"momVerifyTicket"`
-	YoyVerifyTicket    *float64 `json:"yoyVerifyT"`
-	RefundTicket       int      `json:"refundTicket"`
-	MomRefundTicket    *float64 `json:"momRefundTicket"`
-	YoyRefundTicket    *float64 `json:"yoyRefundTicket"`
-	FinishTicket       int      `json:"finishTicket"`
-	MomFinishTicket    *float64 `json:"momFinishTicket"`
-	YoyFinishTicket    *float64 `json:"yoyFinishTicket"`
-	CancelTicket       int      `json:"cancelTicket"`
-	MomCancelTicket    *float64 `json:"momCancelTicket"`
-	YoyCancelTicket    *float64 `json:"yoyCancelTicket"`
-	AfterSaleTicket    int      `json:"afterSaleTicket"`
-	MomAfterSaleTicket *float64 `json:"momAfterSaleTicket"`
-	YoyAfterSaleTicket *float64 `json:"yoyAfterSaleTicket"`
-	OrderAmount        int      `json:"orderAmount"`
-	MomOrderAmount     *float64 `json:"momOrderAmount"`
-	YoyOrderAmount     *float64 `json:"yoyOrderAmount"`
-	VerifyAmount       int      `json:"verifyAmount"`
-	MomVerifyAmount    *float64 `json:"momVerifyAmount"`
-	YoyVerifyAmount    *float64 `json:"yoyVerifyAmount"`
-	RefundAmount       int      `json:"refundAmount"`
-	MomRefundAmount    *float64 `json:"momRefundAmount"`
-	YoyRefundAmount    *float64 `json:"yoyRefundAmount"`
-	FinishAmount       int      `json:"finishAmount"`
-	MomFinishAmount    *float64 `json:"momFinishAmount"`
-	YoyFinishAmount    *float64 `json:"yoyFinishAmount"`
-	CancelAmount       int      `json:"cancelAmount"`
-	MomCancelAmount    *float64 `json:"momCancelAmount"`
-	YoyCancelAmount    *float64 `json:"yoyCancelAmount"`
-	AfterSaleAmount    int      `json:"afterSaleAmount"`
-	MomAfterSaleAmount *float64 `json:"momAfterSaleAmount"`
-	YoyAfterSaleAmount *float64 `json:"yoyAfterSaleAmount"`
+	Order     SummaryMetric `json:"order"`
+	Verify    SummaryMetric `json:"verify"`
+	Refund    SummaryMetric `json:"refund"`
+	Finish    SummaryMetric `json:"finish"`
+	Cancel    SummaryMetric `json:"cancel"`
+	AfterSale SummaryMetric `json:"afterSale"`
+}
+
+type SummaryMetric struct {
+	Ticket odas.Metric `json:"ticket"`
+	Amount odas.Metric `json:"amount"`
+}
+
+// summaryLegacyKeys 兼容服务端历史上拼错的字段名
+var summaryLegacyKeys = map[string]string{
+	"yoyVerifyTicket": "yoyVerifyT",
+}
+
+func (r *SummaryResponse) groups() map[string]*SummaryMetric {
+	return map[string]*SummaryMetric{
+		"order":     &r.Order,
+		"verify":    &r.Verify,
+		"refund":    &r.Refund,
+		"finish":    &r.Finish,
+		"cancel":    &r.Cancel,
+		"afterSale": &r.AfterSale,
+	}
+}
+
+func summaryKeys(prefix string) (value, mom, yoy string) {
+	name := strings.ToUpper(prefix[:1]) + prefix[1:]
+	return prefix, "mom" + name, "yoy" + name
+}
+
+// UnmarshalJSON 解析服务端扁平的 orderTicket/momOrderTicket/yoyOrderTicket 格式，只解析已知字段，
+// 其余字段不论类型均忽略；带小数的数量四舍五入
+func (r *SummaryResponse) UnmarshalJSON(data []byte) error {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	lookup := func(key string) (*float64, error) {
+		v, ok := raw[key]
+		if !ok {
+			if legacy, found := summaryLegacyKeys[key]; found {
+				key = legacy
+				v, ok = raw[legacy]
+			}
+		}
+		if !ok {
+			return nil, nil
+		}
+		var f *float64
+		if err := json.Unmarshal(v, &f); err != nil {
+			return nil, fmt.Errorf("summary %s: %w", key, err)
+		}
+		return f, nil
+	}
+	*r = SummaryResponse{}
+	for prefix, group := range r.groups() {
+		for suffix, m := range map[string]*odas.Metric{"Ticket": &group.Ticket, "Amount": &group.Amount} {
+			value, mom, yoy := summaryKeys(prefix + suffix)
+			v, err := lookup(value)
+			if err != nil {
+				return err
+			}
+			if v != nil {
+				m.Value = int(math.Round(*v))
+			}
+			if m.Mom, err = lookup(mom); err != nil {
+				return err
+			}
+			if m.Yoy, err = lookup(yoy); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// MarshalJSON 按服务端扁平格式输出
+func (r SummaryResponse) MarshalJSON() ([]byte, error) {
+	out := make(map[string]any, 36)
+	for prefix, group := range r.groups() {
+		for suffix, m := range map[string]odas.Metric{"Ticket": group.Ticket, "Amount": group.Amount} {
+			value, mom, yoy := summaryKeys(prefix + suffix)
+			out[value] = m.Value
+			out[mom] = m.Mom
+			out[yoy] = m.Yoy
+		}
+	}
+	return json.Marshal(out)
 }
@@ -0,0 +1,289 @@
+package portrait
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/piaofutong/odas-sdk/odas/order"
+)
+
+// openBandSpan 重新分组与估算中位数时，"61+" 这类不封顶年龄段按该跨度处理
+const openBandSpan = 20
+
+// AgeBand 年龄段 [Min, Max)，Max 为 0 表示不封顶，Unknown 表示年龄未知
+type AgeBand struct {
+	Min     int
+	Max     int
+	Unknown bool
+}
+
+func (b AgeBand) String() string {
+	switch {
+	case b.Unknown:
+		return "未知"
+	case b.Max == 0:
+		return fmt.Sprintf("%d+", b.Min)
+	}
+	return fmt.Sprintf("%d-%d", b.Min, b.Max-1)
+}
+
+// upper 计算用的上限，不封顶年龄段按 openBandSpan 处理
+func (b AgeBand) upper() int {
+	if b.Max == 0 {
+		return b.Min + openBandSpan
+	}
+	return b.Max
+}
+
+var (
+	ageRangePattern = regexp.MustCompile(`^(\d+)\s*(?:-|~|－|—|至|到)\s*(\d+)$`)
+	ageAbovePattern = regexp.MustCompile(`^(?:>=|≥)?(\d+)(\+|以上|及以上)$`)
+	ageBelowPattern = regexp.MustCompile(`^(?:<|＜)?(\d+)(以下|及以下)?$`)
+)
+
+// ParseAgeBand 解析服务端的年龄段标签，支持 "18-25"、"18~25岁"、"61+"、"60岁以上"、"18岁以下"、"<18"、"未知" 等写法。
+// 单独解析时 "60以上" 含 60；与 "18-60" 同时出现时的处理见 AgePyramidFromSummary
+func ParseAgeBand(label string) (AgeBand, error) {
+	band, _, err := parseAgeBand(label)
+	return band, err
+}
+
+// parseAgeBand 同 ParseAgeBand，above 表示标签为 "N以上"，是否含 N 需结合其他年龄段判断
+func parseAgeBand(label string) (band AgeBand, above bool, err error) {
+	s := strings.TrimSpace(label)
+	switch strings.ToLower(s) {
+	case "", "未知", "unknown", "其他", "其它":
+		return AgeBand{Unknown: true}, false, nil
+	}
+	s = strings.ReplaceAll(s, "岁", "")
+	s = strings.ReplaceAll(s, " ", "")
+	if m := ageRangePattern.FindStringSubmatch(s); m != nil {
+		lo, _ := strconv.Atoi(m[1])
+		hi, _ := strconv.Atoi(m[2])
+		if hi < lo {
+			return AgeBand{}, false, fmt.Errorf("invalid age band %q", label)
+		}
+		return AgeBand{Min: lo, Max: hi + 1}, false, nil
+	}
+	if m := ageAbovePattern.FindStringSubmatch(s); m != nil {
+		lo, _ := strconv.Atoi(m[1])
+		return AgeBand{Min: lo}, m[2] == "以上", nil
+	}
+	if m := ageBelowPattern.FindStringSubmatch(s); m != nil && (m[2] != "" || strings.HasPrefix(s, "<") || strings.HasPrefix(s, "＜")) {
+		hi, _ := strconv.Atoi(m[1])
+		if m[2] == "及以下" {
+			hi++
+		}
+		return AgeBand{Min: 0, Max: hi}, false, nil
+	}
+	return AgeBand{}, false, fmt.Errorf("invalid age band %q", label)
+}
+
+// AgeRow 某一年龄段的男女人数，Percent 为占已知年龄人数的比例
+type AgeRow struct {
+	Band    AgeBand
+	Male    float64
+	Female  float64
+	Total   float64
+	Percent float64
+}
+
+// AgePyramid 年龄性别分布。Rows 按年龄升序且互不重叠，年龄未知的人数单独放在 Unknown，不参与占比和中位数
+type AgePyramid struct {
+	Rows    []*AgeRow
+	Unknown AgeRow
+	Male    float64
+	Female  float64
+	// Known 已知年龄的人数，Total 含未知
+	Known float64
+	Total float64
+	// UnknownPercent 年龄未知人数占总数的比例
+	UnknownPercent float64
+}
+
+// NewAgePyramid 合并相同年龄段并计算合计与占比，年龄段重叠时返回错误
+func NewAgePyramid(rows []*AgeRow) (*AgePyramid, error) {
+	p := &AgePyramid{Unknown: AgeRow{Band: AgeBand{Unknown: true}}}
+	merged := make(map[AgeBand]*AgeRow)
+	for _, row := range rows {
+		if row.Band.Unknown {
+			p.Unknown.Male += row.Male
+			p.Unknown.Female += row.Female
+			continue
+		}
+		m, ok := merged[row.Band]
+		if !ok {
+			m = &AgeRow{Band: row.Band}
+			merged[row.Band] = m
+			p.Rows = append(p.Rows, m)
+		}
+		m.Male += row.Male
+		m.Female += row.Female
+	}
+	sort.Slice(p.Rows, func(i, j int) bool { return p.Rows[i].Band.Min < p.Rows[j].Band.Min })
+	for i := 1; i < len(p.Rows); i++ {
+		prev := p.Rows[i-1].Band
+		if prev.Max == 0 || prev.Max > p.Rows[i].Band.Min {
+			return nil, fmt.Errorf("age bands %s and %s overlap", prev, p.Rows[i].Band)
+		}
+	}
+	p.total()
+	return p, nil
+}
+
+func (p *AgePyramid) total() {
+	p.Male, p.Female, p.Known = 0, 0, 0
+	for _, row := range p.Rows {
+		row.Total = row.Male + row.Female
+		p.Male += row.Male
+		p.Female += row.Female
+		p.Known += row.Total
+	}
+	p.Unknown.Total = p.Unknown.Male + p.Unknown.Female
+	p.Total = p.Known + p.Unknown.Total
+	for _, row := range p.Rows {
+		row.Percent = 0
+		if p.Known > 0 {
+			row.Percent = row.Total / p.Known
+		}
+	}
+	p.UnknownPercent = 0
+	if p.Total > 0 {
+		p.UnknownPercent = p.Unknown.Total / p.Total
+	}
+}
+
+// AgePyramidFromSummary 解析 SexAgeSummaryReq 的年龄段标签。
+// "N以上" 与截止到 N 的年龄段(如 "18-60")同时出现时，按大于 N 处理，避免两段重叠
+func AgePyramidFromSummary(r *AgeSummaryResponse) (*AgePyramid, error) {
+	rows := make([]*AgeRow, 0, len(r.List))
+	ends := make(map[int]bool)
+	var above []*AgeRow
+	for _, item := range r.List {
+		band, isAbove, err := parseAgeBand(item.AgeGroup)
+		if err != nil {
+			return nil, err
+		}
+		row := &AgeRow{Band: band, Male: float64(item.Male), Female: float64(item.Female)}
+		if isAbove {
+			above = append(above, row)
+		} else if !band.Unknown && band.Max > 0 {
+			ends[band.Max-1] = true
+		}
+		rows = append(rows, row)
+	}
+	for _, row := range above {
+		if ends[row.Band.Min] {
+			row.Band.Min++
+		}
+	}
+	return NewAgePyramid(rows)
+}
+
+// AgePyramidFromPreBooking 汇总 PreBookingAgeGenderDistReq 各时间点的年龄性别人数
+func AgePyramidFromPreBooking(r *order.PreBookingAgeGenderDistResponse) (*AgePyramid, error) {
+	bands := []AgeBand{{0, 8, false}, {8, 18, false}, {18, 28, false}, {28, 41, false}, {41, 61, false}, {61, 0, false}}
+	rows := make([]*AgeRow, len(bands))
+	for i, band := range bands {
+		rows[i] = &AgeRow{Band: band}
+	}
+	for _, item := range r.List {
+		male := []int{item.MaleAge0to7, item.MaleAge8to17, item.MaleAge18to27, item.MaleAge28to40, item.MaleAge41to60, item.MaleAge61plus}
+		female := []int{item.FemaleAge0to7, item.FemaleAge8to17, item.FemaleAge18to27, item.FemaleAge28to40, item.FemaleAge41to60, item.FemaleAge61plus}
+		for i := range rows {
+			rows[i].Male += float64(male[i])
+			rows[i].Female += float64(female[i])
+		}
+	}
+	return NewAgePyramid(rows)
+}
+
+// Rebin 按分组边界重新分组，如 Rebin(0, 10, 20, 30, 40, 50, 60) 得到 0-9 ... 50-59、60+ 共 7 组。
+// 原年龄段跨越新边界时按年龄均匀分布拆分人数，因此结果可能含小数
+func (p *AgePyramid) Rebin(edges ...int) (*AgePyramid, error) {
+	if len(edges) == 0 || edges[0] != 0 {
+		return nil, fmt.Errorf("edges must start at 0")
+	}
+	for i := 1; i < len(edges); i++ {
+		if edges[i] <= edges[i-1] {
+			return nil, fmt.Errorf("edges must be increasing")
+		}
+	}
+	rows := make([]*AgeRow, len(edges))
+	for i, lo := range edges {
+		band := AgeBand{Min: lo}
+		if i+1 < len(edges) {
+			band.Max = edges[i+1]
+		}
+		rows[i] = &AgeRow{Band: band}
+	}
+	for _, src := range p.Rows {
+		lo, hi := src.Band.Min, src.Band.upper()
+		span := float64(hi - lo)
+		for _, dst := range rows {
+			dlo, dhi := dst.Band.Min, dst.Band.Max
+			if dhi == 0 {
+				dhi = math.MaxInt
+			}
+			overlap := min(hi, dhi) - max(lo, dlo)
+			if overlap <= 0 {
+				continue
+			}
+			share := float64(overlap) / span
+			dst.Male += src.Male * share
+			dst.Female += src.Female * share
+		}
+	}
+	rows = append(rows, &AgeRow{Band: AgeBand{Unknown: true}, Male: p.Unknown.Male, Female: p.Unknown.Female})
+	return NewAgePyramid(rows)
+}
+
+// Decades 按 10 岁一组重新分组，maxAge 及以上合为一组，如 Decades(70) 得到 0-9 ... 60-69、70+
+func (p *AgePyramid) Decades(maxAge int) (*AgePyramid, error) {
+	var edges []int
+	for age := 0; age <= maxAge; age += 10 {
+		edges = append(edges, age)
+	}
+	return p.Rebin(edges...)
+}
+
+// MedianAge 已知年龄人数的中位年龄，在所在年龄段内线性插值估算；无数据时返回 false
+func (p *AgePyramid) MedianAge() (float64, bool) {
+	return p.median(func(row *AgeRow) float64 { return row.Total })
+}
+
+// MedianAgeMale 男性中位年龄
+func (p *AgePyramid) MedianAgeMale() (float64, bool) {
+	return p.median(func(row *AgeRow) float64 { return row.Male })
+}
+
+// MedianAgeFemale 女性中位年龄
+func (p *AgePyramid) MedianAgeFemale() (float64, bool) {
+	return p.median(func(row *AgeRow) float64 { return row.Female })
+}
+
+func (p *AgePyramid) median(count func(*AgeRow) float64) (float64, bool) {
+	var total float64
+	for _, row := range p.Rows {
+		total += count(row)
+	}
+	if total <= 0 {
+		return 0, false
+	}
+	half := total / 2
+	var cum float64
+	for _, row := range p.Rows {
+		c := count(row)
+		if c > 0 && cum+c >= half {
+			lo, hi := float64(row.Band.Min), float64(row.Band.upper())
+			return lo + (half-cum)/c*(hi-lo), true
+		}
+		cum += c
+	}
+	last := p.Rows[len(p.Rows)-1].Band
+	return float64(last.upper()), true
+}
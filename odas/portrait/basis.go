@@ -0,0 +1,60 @@
+package portrait
+
+import "fmt"
+
+// Basis 画像统计口径，零值按预订口径处理
+type Basis int
+
+const (
+	BasisBooking Basis = iota + 1 // 预订
+	BasisTicket                   // 出票
+	BasisVerify                   // 验证
+)
+
+func (b Basis) String() string {
+	switch b {
+	case BasisBooking:
+		return "booking"
+	case BasisTicket:
+		return "ticket"
+	case BasisVerify:
+		return "verify"
+	}
+	return fmt.Sprintf("Basis(%d)", int(b))
+}
+
+// check 校验口径是否被接口支持，零值与预订口径总是支持
+func (b Basis) check(name string, supported ...Basis) error {
+	if b == 0 || b == BasisBooking {
+		return nil
+	}
+	for _, s := range supported {
+		if s == b {
+			return nil
+		}
+	}
+	return fmt.Errorf("portrait %s does not support basis %s", name, b)
+}
+
+// endpoint 按口径拼接接口路径，如 ageSummary -> ageSummaryByVerify。
+// 与 check 使用同一支持列表，不支持的口径返回服务端不存在的路径，不会退回预订口径
+func (b Basis) endpoint(name string, supported ...Basis) string {
+	if b.check(name, supported...) != nil {
+		return fmt.Sprintf("/v4/portrait/%s/unsupported/%s", name, b)
+	}
+	switch b {
+	case BasisTicket:
+		return fmt.Sprintf("/v4/portrait/%sByTicket", name)
+	case BasisVerify:
+		return fmt.Sprintf("/v4/portrait/%sByVerify", name)
+	}
+	return fmt.Sprintf("/v4/portrait/%s", name)
+}
+
+// fallback 接口支持该口径时原样返回，否则返回预订口径，用于 Profile 等组合查询
+func fallback(basis Basis, supported ...Basis) Basis {
+	if basis.check("", supported...) != nil {
+		return BasisBooking
+	}
+	return basis
+}
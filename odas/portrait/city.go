@@ -7,6 +7,7 @@ import (
 )
 
 type CityOptions struct {
+	Basis    Basis  `json:"basis"`
 	Province string `json:"province"`
 	Unknown  bool   `json:"unknown"`
 	Limit    int    `json:"limit"`
@@ -14,6 +15,13 @@ type CityOptions struct {
 
 type CityOption func(options *CityOptions)
 
+// WithCityBasis 统计口径，支持预订、验证，其他口径在 Do 时返回错误
+func WithCityBasis(basis Basis) CityOption {
+	return func(options *CityOptions) {
+		options.Basis = basis
+	}
+}
+
 func WithCityLimit(limit int) CityOption {
 	return func(options *CityOptions) {
 		options.Limit = limit
@@ -73,7 +81,15 @@ func (r CityReq) Api() string {
 	if r.Options.Province != "" {
 		params.Add("province", r.Options.Province)
 	}
-	return fmt.Sprintf("/v4/portrait/city?%s", params.Encode())
+	return fmt.Sprintf("%s?%s", r.Options.Basis.endpoint("city", cityBases...), params.Encode())
+}
+
+// cityBases 接口支持的非预订口径
+var cityBases = []Basis{BasisVerify}
+
+// Validate 拒绝接口不支持的统计口径
+func (r CityReq) Validate() error {
+	return r.Options.Basis.check("city", cityBases...)
 }
 
 type CityRankResponse struct {
@@ -6,11 +6,19 @@ import (
 )
 
 type FellowOptions struct {
+	Basis    Basis
 	Province string
 }
 
 type FellowOption func(options *FellowOptions)
 
+// WithFellowBasis 统计口径，支持预订、出票，其他口径在 Do 时返回错误
+func WithFellowBasis(basis Basis) FellowOption {
+	return func(options *FellowOptions) {
+		options.Basis = basis
+	}
+}
+
 func WithFellowProvince(province string) FellowOption {
 	return func(options *FellowOptions) {
 		options.Province = province
@@ -39,7 +47,15 @@ func (r *FellowReq) Api() string {
 	if r.Options.Province != "" {
 		params.Add("province", r.Options.Province)
 	}
-	return fmt.Sprintf("/v4/portrait/fellow?%s", params.Encode())
+	return fmt.Sprintf("%s?%s", r.Options.Basis.endpoint("fellow", fellowBases...), params.Encode())
+}
+
+// fellowBases 接口支持的非预订口径
+var fellowBases = []Basis{BasisTicket}
+
+// Validate 拒绝接口不支持的统计口径
+func (r *FellowReq) Validate() error {
+	return r.Options.Basis.check("fellow", fellowBases...)
 }
 
 type FellowResponse struct {
@@ -1,55 +1,32 @@
 package portrait
 
 import (
-	"fmt"
-
 	"github.com/piaofutong/odas-sdk/odas"
 )
 
-type FellowByTicketOptions struct {
-	Province string
-}
+// Deprecated: 使用 FellowOptions
+type FellowByTicketOptions = FellowOptions
 
-type FellowByTicketOption func(options *FellowByTicketOptions)
+// Deprecated: 使用 FellowOption
+type FellowByTicketOption = FellowOption
 
+// Deprecated: 使用 WithFellowProvince
 func WithFellowByTicketProvince(province string) FellowByTicketOption {
-	return func(options *FellowByTicketOptions) {
-		options.Province = province
-	}
+	return WithFellowProvince(province)
 }
 
-// FellowByTicketReq 同行人数
-type FellowByTicketReq struct {
-	odas.Req
-	Options *FellowByTicketOptions
-}
+// FellowByTicketReq 同行人数(出票维度)
+//
+// Deprecated: 使用 FellowReq 与 WithFellowBasis(BasisTicket)
+type FellowByTicketReq = FellowReq
 
+// Deprecated: 使用 NewFellowReq(req, WithFellowBasis(BasisTicket))
 func NewFellowByTicketReq(req *odas.Req, opt ...FellowByTicketOption) *FellowByTicketReq {
-	options := &FellowByTicketOptions{}
-	for _, p := range opt {
-		p(options)
-	}
-	return &FellowByTicketReq{
-		Req:     *req,
-		Options: options,
-	}
+	return NewFellowReq(req, append([]FellowOption{WithFellowBasis(BasisTicket)}, opt...)...)
 }
 
-func (r *FellowByTicketReq) Api() string {
-	params := r.Req.Params()
-	if r.Options.Province != "" {
-		params.Add("province", r.Options.Province)
-	}
-	return fmt.Sprintf("/v4/portrait/fellowByTicket?%s", params.Encode())
-}
+// Deprecated: 使用 FellowResponse
+type FellowByTicketResponse = FellowResponse
 
-type FellowByTicketResponse struct {
-	Total int                   `json:"total"`
-	List  []*FellowByTicketList `json:"list"`
-}
-
-type FellowByTicketList struct {
-	Name  string  `json:"name"`
-	Count int     `json:"count"`
-	Rate  float64 `json:"rate"`
-}
+// Deprecated: 使用 FellowList
+type FellowByTicketList = FellowList
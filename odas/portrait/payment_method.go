@@ -1,18 +1,29 @@
 package portrait
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
 	"github.com/piaofutong/odas-sdk/odas"
 	"strconv"
 )
 
 type PayMethodOptions struct {
+	Basis    Basis  `json:"basis"`
 	Province string `json:"province"`
 	Limit    int    `json:"limit"`
 }
 
 type PaymentMethodOption func(options *PayMethodOptions)
 
+// WithPaymentMethodBasis 统计口径，支持预订、出票，其他口径在 Do 时返回错误。
+// 各口径的响应格式不同，可统一解析到 PaymentMethodSummary
+func WithPaymentMethodBasis(basis Basis) PaymentMethodOption {
+	return func(options *PayMethodOptions) {
+		options.Basis = basis
+	}
+}
+
 func WithPaymentMethodLimit(limit int) PaymentMethodOption {
 	return func(options *PayMethodOptions) {
 		options.Limit = limit
@@ -50,11 +61,59 @@ func (r PaymentMethodReq) Api() string {
 	if r.Options.Province != "" {
 		params.Add("province", r.Options.Province)
 	}
-	return fmt.Sprintf("/v4/portrait/paymentMethod?%s", params.Encode())
+	return fmt.Sprintf("%s?%s", r.Options.Basis.endpoint("paymentMethod", paymentMethodBases...), params.Encode())
 }
 
+// paymentMethodBases 接口支持的非预订口径
+var paymentMethodBases = []Basis{BasisTicket}
+
+// Validate 拒绝接口不支持的统计口径
+func (r PaymentMethodReq) Validate() error {
+	return r.Options.Basis.check("paymentMethod", paymentMethodBases...)
+}
+
+// PaymentMethodResponse 预订口径的支付渠道
 type PaymentMethodResponse struct {
 	Channel string  `json:"name"`
 	Total   int     `json:"count"`
 	Rate    float64 `json:"rate"`
 }
+
+// PaymentMethodSummary 各口径统一的支付渠道分布。
+// 预订口径接口只返回渠道名称、数量和占比，解析后 ChannelId、Amount、AmountRate 为 0
+type PaymentMethodSummary struct {
+	Total int                  `json:"total"`
+	List  []*PaymentMethodItem `json:"list"`
+}
+
+type PaymentMethodItem struct {
+	ChannelId       int     `json:"id"`
+	ChannelName     string  `json:"name"`
+	TicketCount     int     `json:"ticket_count"`
+	Amount          int     `json:"amount"`
+	AmountRate      float64 `json:"amount_rate"`
+	TicketCountRate float64 `json:"ticket_count_rate"`
+}
+
+// UnmarshalJSON 兼容出票口径的 {"total":..,"list":[..]} 与预订口径的数组两种格式
+func (s *PaymentMethodSummary) UnmarshalJSON(data []byte) error {
+	if trimmed := bytes.TrimSpace(data); len(trimmed) == 0 || trimmed[0] != '[' {
+		type summary PaymentMethodSummary
+		return json.Unmarshal(data, (*summary)(s))
+	}
+	var list []*PaymentMethodResponse
+	if err := json.Unmarshal(data, &list); err != nil {
+		return err
+	}
+	s.Total = 0
+	s.List = make([]*PaymentMethodItem, 0, len(list))
+	for _, item := range list {
+		s.Total += item.Total
+		s.List = append(s.List, &PaymentMethodItem{
+			ChannelName:     item.Channel,
+			TicketCount:     item.Total,
+			TicketCountRate: item.Rate,
+		})
+	}
+	return nil
+}
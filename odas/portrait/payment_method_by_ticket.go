@@ -1,69 +1,37 @@
 package portrait
 
 import (
-	"fmt"
-	"strconv"
-
 	"github.com/piaofutong/odas-sdk/odas"
 )
 
-type PaymentMethodByTicketOptions struct {
-	Province string `json:"province"`
-	Limit    int    `json:"limit"`
-}
+// Deprecated: 使用 PayMethodOptions
+type PaymentMethodByTicketOptions = PayMethodOptions
 
-type PaymentMethodByTicketOption func(options *PaymentMethodByTicketOptions)
+// Deprecated: 使用 PaymentMethodOption
+type PaymentMethodByTicketOption = PaymentMethodOption
 
+// Deprecated: 使用 WithPaymentMethodLimit
 func WithPaymentMethodByTicketLimit(limit int) PaymentMethodByTicketOption {
-	return func(options *PaymentMethodByTicketOptions) {
-		options.Limit = limit
-	}
+	return WithPaymentMethodLimit(limit)
 }
 
+// Deprecated: 使用 WithPaymentMethodProvince
 func WithPaymentMethodByTicketProvince(province string) PaymentMethodByTicketOption {
-	return func(options *PaymentMethodByTicketOptions) {
-		options.Province = province
-	}
+	return WithPaymentMethodProvince(province)
 }
 
-// PaymentMethodByTicketReq 支付渠道
-type PaymentMethodByTicketReq struct {
-	odas.Req
-	Options *PaymentMethodByTicketOptions
-}
+// PaymentMethodByTicketReq 支付渠道(出票维度)
+//
+// Deprecated: 使用 PaymentMethodReq 与 WithPaymentMethodBasis(BasisTicket)
+type PaymentMethodByTicketReq = PaymentMethodReq
 
+// Deprecated: 使用 NewPaymentMethodReq(req, WithPaymentMethodBasis(BasisTicket))
 func NewPaymentMethodByTicketReq(req *odas.Req, opt ...PaymentMethodByTicketOption) *PaymentMethodByTicketReq {
-	options := &PaymentMethodByTicketOptions{}
-	for _, p := range opt {
-		p(options)
-	}
-	return &PaymentMethodByTicketReq{
-		Req:     *req,
-		Options: options,
-	}
+	return NewPaymentMethodReq(req, append([]PaymentMethodOption{WithPaymentMethodBasis(BasisTicket)}, opt...)...)
 }
 
-func (r PaymentMethodByTicketReq) Api() string {
-	params := r.Req.Params()
-	if r.Options.Limit > 0 {
-		params.Add("limit", strconv.Itoa(r.Options.Limit))
-	}
-	if r.Options.Province != "" {
-		params.Add("province", r.Options.Province)
-	}
-	return fmt.Sprintf("/v4/portrait/paymentMethodByTicket?%s", params.Encode())
-}
+// Deprecated: 使用 PaymentMethodSummary
+type PaymentMethodByTicketResponse = PaymentMethodSummary
 
-type PaymentMethodByTicketResponse struct {
-	Total int                              `json:"total"`
-	List  []*PaymentMethodByTicketListItem `json:"list"`
-}
-
-type PaymentMethodByTicketListItem struct {
-	ChannelId       int     `json:"id"`
-	ChannelName     string  `json:"name"`
-	TicketCount     int     `json:"ticket_count"`
-	Amount          int     `json:"amount"`
-	AmountRate      float64 `json:"amount_rate"`
-	TicketCountRate float64 `json:"ticket_count_rate"`
-}
+// Deprecated: 使用 PaymentMethodItem
+type PaymentMethodByTicketListItem = PaymentMethodItem
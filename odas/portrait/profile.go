@@ -0,0 +1,197 @@
+package portrait
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/piaofutong/odas-sdk/odas"
+)
+
+// 画像各组成部分，用作 VisitorProfile.Errors 的键
+const (
+	SectionSexAge   = "sexAge"
+	SectionProvince = "province"
+	SectionCity     = "city"
+	SectionFellow   = "fellow"
+	SectionPayment  = "payment"
+	SectionLocation = "location"
+)
+
+// ProfileReq 画像查询参数，各子接口的选项统一在这里设置，子接口不支持的选项会被忽略
+type ProfileReq struct {
+	odas.Req
+	odas.DateRangeCompareReq
+	Province string
+	Unknown  bool
+	Limit    int
+}
+
+// VisitorProfile 游客画像。
+// 部分接口没有对应口径的版本时使用预订口径：省、市排行和客源地排行没有出票口径，同行人数和支付渠道没有验证口径
+type VisitorProfile struct {
+	Basis     Basis
+	SexAge    *AgeSummaryResponse
+	Provinces []*ProvinceRankResponse
+	Cities    []*CityRankResponse
+	Fellow    *FellowResponse
+	Payment   *PaymentMethodSummary
+	Locations *CountryProvinceLocationRankResponse
+	// Errors 请求失败的部分，键为 Section* 常量
+	Errors map[string]error
+}
+
+type profileFetch struct {
+	section string
+	do      func() error
+}
+
+// Profile 按口径并发查询性别年龄、省市排行、同行人数、支付渠道和客源地排行，合并为一份画像。
+// 部分接口失败时仍返回其余结果，失败明细见 Errors；全部失败时返回错误。
+// ctx 取消后不再发起新请求并立即返回 ctx.Err()
+func Profile(ctx context.Context, iam *odas.IAM, req *ProfileReq, basis Basis, opts ...odas.Option) (*VisitorProfile, error) {
+	if basis < BasisBooking || basis > BasisVerify {
+		return nil, fmt.Errorf("unknown portrait basis %v", basis)
+	}
+	p := &VisitorProfile{Basis: basis, Errors: make(map[string]error)}
+	fetches := profileFetches(iam, req, basis, p, opts)
+
+	var (
+		mutex sync.Mutex
+		wg    sync.WaitGroup
+	)
+	for _, f := range fetches {
+		wg.Add(1)
+		go func(f profileFetch) {
+			defer wg.Done()
+			err := ctx.Err()
+			if err == nil {
+				err = f.do()
+			}
+			if err != nil {
+				mutex.Lock()
+				p.Errors[f.section] = fmt.Errorf("%s: %w", f.section, err)
+				mutex.Unlock()
+			}
+		}(f)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-done:
+	}
+
+	if len(p.Errors) == len(fetches) {
+		sections := make([]string, 0, len(p.Errors))
+		for section := range p.Errors {
+			sections = append(sections, section)
+		}
+		sort.Strings(sections)
+		errs := make([]error, 0, len(sections))
+		for _, section := range sections {
+			errs = append(errs, p.Errors[section])
+		}
+		return nil, errors.Join(errs...)
+	}
+	return p, nil
+}
+
+// profileFetches 按口径组装各子接口请求，每个请求成功后只写入画像中各自的字段
+func profileFetches(iam *odas.IAM, req *ProfileReq, basis Basis, p *VisitorProfile, opts []odas.Option) []profileFetch {
+	base := &req.Req
+	compare := &req.DateRangeCompareReq
+
+	return []profileFetch{
+		{SectionSexAge, func() error {
+			var r AgeSummaryResponse
+			err := iam.Do(NewSexAgeSummaryReq(base,
+				WithSexAgeBasis(basis),
+				WithSexAgeProvince(req.Province),
+				WithSexAgeUnknown(req.Unknown),
+			), &r, opts...)
+			if err != nil {
+				return err
+			}
+			p.SexAge = &r
+			return nil
+		}},
+		{SectionProvince, func() error {
+			var r []*ProvinceRankResponse
+			err := iam.Do(NewProvinceReq(base, compare,
+				WithProvinceBasis(fallback(basis, provinceBases...)),
+				WithProvinceLimit(req.Limit),
+				WithProvinceUnknown(req.Unknown),
+			), &r, opts...)
+			if err != nil {
+				return err
+			}
+			p.Provinces = r
+			return nil
+		}},
+		{SectionCity, func() error {
+			var r []*CityRankResponse
+			err := iam.Do(NewCityReq(base, compare,
+				WithCityBasis(fallback(basis, cityBases...)),
+				WithCityLimit(req.Limit),
+				WithCityUnknown(req.Unknown),
+				WithCityProvince(req.Province),
+			), &r, opts...)
+			if err != nil {
+				return err
+			}
+			p.Cities = r
+			return nil
+		}},
+		{SectionFellow, func() error {
+			var r FellowResponse
+			err := iam.Do(NewFellowReq(base, WithFellowBasis(fallback(basis, fellowBases...)), WithFellowProvince(req.Province)), &r, opts...)
+			if err != nil {
+				return err
+			}
+			p.Fellow = &r
+			return nil
+		}},
+		{SectionPayment, func() error {
+			var r PaymentMethodSummary
+			err := iam.Do(NewPaymentMethodReq(base,
+				WithPaymentMethodBasis(fallback(basis, paymentMethodBases...)),
+				WithPaymentMethodLimit(req.Limit),
+				WithPaymentMethodProvince(req.Province),
+			), &r, opts...)
+			if err != nil {
+				return err
+			}
+			p.Payment = &r
+			return nil
+		}},
+		{SectionLocation, func() error {
+			var r CountryProvinceLocationRankResponse
+			var err error
+			if basis == BasisVerify {
+				err = iam.Do(NewVerifiedCountryProvinceLocationRankReq(base,
+					WithVerifiedCountryProvinceLocationRankLimit(req.Limit),
+					WithVerifiedCountryProvinceLocationRankUnknown(req.Unknown),
+					WithVerifiedCountryProvinceLocationRankProvince(req.Province),
+				), &r, opts...)
+			} else {
+				loc := NewBookingCountryProvinceLocationRankReq(base)
+				loc.Limit = req.Limit
+				loc.Unknown = req.Unknown
+				err = iam.Do(loc, &r, opts...)
+			}
+			if err != nil {
+				return err
+			}
+			p.Locations = &r
+			return nil
+		}},
+	}
+}
@@ -7,12 +7,20 @@ import (
 )
 
 type ProvinceOptions struct {
-	Limit   int  `json:"limit"`
-	Unknown bool `json:"unknown"`
+	Basis   Basis `json:"basis"`
+	Limit   int   `json:"limit"`
+	Unknown bool  `json:"unknown"`
 }
 
 type ProvinceOption func(options *ProvinceOptions)
 
+// WithProvinceBasis 统计口径，支持预订、验证，其他口径在 Do 时返回错误
+func WithProvinceBasis(basis Basis) ProvinceOption {
+	return func(options *ProvinceOptions) {
+		options.Basis = basis
+	}
+}
+
 func WithProvinceLimit(limit int) ProvinceOption {
 	return func(options *ProvinceOptions) {
 		options.Limit = limit
@@ -62,7 +70,15 @@ func (r ProvinceReq) Api() string {
 	if r.CompareEnd != "" {
 		params.Add("compareEnd", r.CompareEnd)
 	}
-	return fmt.Sprintf("/v4/portrait/province?%s", params.Encode())
+	return fmt.Sprintf("%s?%s", r.Options.Basis.endpoint("province", provinceBases...), params.Encode())
+}
+
+// provinceBases 接口支持的非预订口径
+var provinceBases = []Basis{BasisVerify}
+
+// Validate 拒绝接口不支持的统计口径
+func (r ProvinceReq) Validate() error {
+	return r.Options.Basis.check("province", provinceBases...)
 }
 
 type ProvinceRankResponse struct {
@@ -7,12 +7,20 @@ import (
 )
 
 type SexAgeOptions struct {
+	Basis    Basis  `json:"basis"`
 	Province string `json:"province"`
 	Unknown  bool   `json:"unknown"`
 }
 
 type SexAgeOption func(options *SexAgeOptions)
 
+// WithSexAgeBasis 统计口径，支持预订、出票、验证，其他口径在 Do 时返回错误
+func WithSexAgeBasis(basis Basis) SexAgeOption {
+	return func(options *SexAgeOptions) {
+		options.Basis = basis
+	}
+}
+
 func WithSexAgeUnknown(unknown bool) SexAgeOption {
 	return func(options *SexAgeOptions) {
 		options.Unknown = unknown
@@ -50,7 +58,15 @@ func (r SexAgeSummaryReq) Api() string {
 	if r.Options.Province != "" {
 		params.Add("province", r.Options.Province)
 	}
-	return fmt.Sprintf("/v4/portrait/ageSummary?%s", params.Encode())
+	return fmt.Sprintf("%s?%s", r.Options.Basis.endpoint("ageSummary", ageSummaryBases...), params.Encode())
+}
+
+// ageSummaryBases 接口支持的非预订口径
+var ageSummaryBases = []Basis{BasisTicket, BasisVerify}
+
+// Validate 拒绝接口不支持的统计口径
+func (r SexAgeSummaryReq) Validate() error {
+	return r.Options.Basis.check("ageSummary", ageSummaryBases...)
 }
 
 type AgeSummaryResponse struct {
@@ -1,44 +1,26 @@
 package portrait
 
 import (
-	"fmt"
-	"strconv"
-
 	"github.com/piaofutong/odas-sdk/odas"
 )
 
-type SexAgeByTicketOptions struct {
-	Province string `json:"province"`
-	Unknown  bool   `json:"unknown"`
-}
+// Deprecated: 使用 SexAgeOptions
+type SexAgeByTicketOptions = SexAgeOptions
 
-type SexAgeByTicketOption func(options *SexAgeByTicketOptions)
+// Deprecated: 使用 SexAgeOption
+type SexAgeByTicketOption = SexAgeOption
 
-// SexAgeSummaryByTicketReq 性别年龄分布
+// SexAgeSummaryByTicketReq 性别年龄分布(出票维度)
+//
+// Deprecated: 使用 SexAgeSummaryReq 与 WithSexAgeBasis(BasisTicket)
 type SexAgeSummaryByTicketReq struct {
-	odas.Req
-	Sid     int
-	Options *SexAgeByTicketOptions
+	SexAgeSummaryReq
+	Sid int
 }
 
+// Deprecated: 使用 NewSexAgeSummaryReq(req, WithSexAgeBasis(BasisTicket))
 func NewSexAgeSummaryByTicketReq(req *odas.Req, opt ...SexAgeByTicketOption) *SexAgeSummaryByTicketReq {
-	options := &SexAgeByTicketOptions{}
-	for _, p := range opt {
-		p(options)
-	}
 	return &SexAgeSummaryByTicketReq{
-		Req:     *req,
-		Options: options,
-	}
-}
-
-func (r SexAgeSummaryByTicketReq) Api() string {
-	params := r.Req.Params()
-	if r.Options.Unknown {
-		params.Add("unknown", strconv.FormatBool(r.Options.Unknown))
-	}
-	if r.Options.Province != "" {
-		params.Add("province", r.Options.Province)
+		SexAgeSummaryReq: *NewSexAgeSummaryReq(req, append([]SexAgeOption{WithSexAgeBasis(BasisTicket)}, opt...)...),
 	}
-	return fmt.Sprintf("/v4/portrait/ageSummaryByTicket?%s", params.Encode())
 }
@@ -0,0 +1,271 @@
+package product
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+
+	"github.com/piaofutong/odas-sdk/odas"
+	"github.com/piaofutong/odas-sdk/odas/order"
+	"github.com/piaofutong/odas-sdk/odas/report"
+)
+
+const (
+	// DefaultCatalogPageSize 遍历票列表时每页的条数
+	DefaultCatalogPageSize = 100
+	// maxCatalogPages 服务端分页信息异常时的翻页上限
+	maxCatalogPages = 1000
+)
+
+// WalkTicketList 从第 1 页开始逐页查询票列表，每页回调一次，回调返回错误时停止并返回该错误。
+// 响应带 pagination.pages 时以其为准(服务端可能限制每页条数)，否则返回条数不足一页时结束；空页总是结束
+func WalkTicketList(iam *odas.IAM, req *odas.Req, pageSize int, fn func(page *TicketListResponse) error, opts ...odas.Option) error {
+	if pageSize <= 0 {
+		pageSize = DefaultCatalogPageSize
+	}
+	for page := 1; page <= maxCatalogPages; page++ {
+		var r TicketListResponse
+		if err := iam.Do(NewTicketListReq(req, &odas.DateRangeCompareReq{}, page, pageSize), &r, opts...); err != nil {
+			return fmt.Errorf("ticket list page %d: %w", page, err)
+		}
+		if len(r.List) == 0 {
+			return nil
+		}
+		if err := fn(&r); err != nil {
+			return err
+		}
+		if r.Pagination.Pages > 0 {
+			if page >= r.Pagination.Pages {
+				return nil
+			}
+		} else if len(r.List) < pageSize {
+			return nil
+		}
+	}
+	return fmt.Errorf("ticket list exceeds %d pages", maxCatalogPages)
+}
+
+// Ticket 门票目录项，只记录名称与所属景区，Lid 为 0 表示未知所属景区。
+// 销售数据随查询时间段变化，不在目录中保存，需要时直接使用 TicketListResponse
+type Ticket struct {
+	TicketId   int    `json:"ticketId"`
+	TicketName string `json:"ticketName"`
+	Lid        int    `json:"lid,omitempty"`
+}
+
+// Catalog 按票 id 索引的本地门票目录，用于给只带 id 的响应补全名称。零值可直接使用
+type Catalog struct {
+	// PageSize 加载时每页的条数，0 使用 DefaultCatalogPageSize
+	PageSize int
+
+	mutex   sync.RWMutex
+	tickets map[int]*Ticket
+	lands   map[int]string
+}
+
+func NewCatalog() *Catalog {
+	return &Catalog{
+		tickets: make(map[int]*Ticket),
+		lands:   make(map[int]string),
+	}
+}
+
+// Add 登记门票，已存在时只补全为空的名称与景区；返回是否新增
+func (c *Catalog) Add(t *Ticket) bool {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.tickets == nil {
+		c.tickets = make(map[int]*Ticket)
+	}
+	old, ok := c.tickets[t.TicketId]
+	if !ok {
+		copied := *t
+		c.tickets[t.TicketId] = &copied
+		return true
+	}
+	if old.TicketName == "" {
+		old.TicketName = t.TicketName
+	}
+	if old.Lid == 0 {
+		old.Lid = t.Lid
+	}
+	return false
+}
+
+// RegisterLand 登记景区名称，票列表接口不返回景区名称
+func (c *Catalog) RegisterLand(lid int, name string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.lands == nil {
+		c.lands = make(map[int]string)
+	}
+	c.lands[lid] = name
+}
+
+// Load 按 req 的景区与时间段遍历全部票列表并登记到目录，返回本次新增的门票数
+func (c *Catalog) Load(iam *odas.IAM, req *odas.Req, opts ...odas.Option) (int, error) {
+	return c.load(iam, req, 0, opts)
+}
+
+// LoadLids 逐个景区遍历票列表，登记的门票带所属景区 lid，便于按 lid 查找门票
+func (c *Catalog) LoadLids(iam *odas.IAM, req *odas.Req, lids []int, opts ...odas.Option) (int, error) {
+	var added int
+	for _, lid := range lids {
+		r := *req
+		r.Lid = strconv.Itoa(lid)
+		n, err := c.load(iam, &r, lid, opts)
+		added += n
+		if err != nil {
+			return added, fmt.Errorf("lid %d: %w", lid, err)
+		}
+	}
+	return added, nil
+}
+
+func (c *Catalog) load(iam *odas.IAM, req *odas.Req, lid int, opts []odas.Option) (int, error) {
+	var added int
+	err := WalkTicketList(iam, req, c.PageSize, func(page *TicketListResponse) error {
+		for _, item := range page.List {
+			if c.Add(&Ticket{TicketId: item.TicketId, TicketName: item.TicketName, Lid: lid}) {
+				added++
+			}
+		}
+		return nil
+	}, opts...)
+	return added, err
+}
+
+// Ticket 按 id 查找门票，返回副本，修改不影响目录
+func (c *Catalog) Ticket(id int) (*Ticket, bool) {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	t, ok := c.tickets[id]
+	if !ok {
+		return nil, false
+	}
+	copied := *t
+	return &copied, true
+}
+
+// Name 门票名称，未登记时返回空
+func (c *Catalog) Name(id int) string {
+	if t, ok := c.Ticket(id); ok {
+		return t.TicketName
+	}
+	return ""
+}
+
+// LandName 景区名称，未登记时返回空
+func (c *Catalog) LandName(lid int) string {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return c.lands[lid]
+}
+
+func (c *Catalog) Len() int {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return len(c.tickets)
+}
+
+// Tickets 全部门票的副本，按 id 升序
+func (c *Catalog) Tickets() []*Ticket {
+	return c.filter(func(*Ticket) bool { return true })
+}
+
+// TicketsByLid 某景区的门票，只包含通过 LoadLids 加载或登记时带 lid 的门票
+func (c *Catalog) TicketsByLid(lid int) []*Ticket {
+	return c.filter(func(t *Ticket) bool { return t.Lid == lid })
+}
+
+func (c *Catalog) filter(keep func(*Ticket) bool) []*Ticket {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	var out []*Ticket
+	for _, t := range c.tickets {
+		if keep(t) {
+			copied := *t
+			out = append(out, &copied)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].TicketId < out[j].TicketId })
+	return out
+}
+
+// Missing 目录中没有的票 id，按出现顺序去重
+func (c *Catalog) Missing(ids ...int) []int {
+	var out []int
+	seen := make(map[int]bool)
+	for _, id := range ids {
+		if _, ok := c.Ticket(id); !ok && !seen[id] {
+			seen[id] = true
+			out = append(out, id)
+		}
+	}
+	return out
+}
+
+// SalesDetailEntry 补全门票信息的销售明细，Ticket 为空表示目录中没有该票
+type SalesDetailEntry struct {
+	*SalesDetailResponse
+	Ticket *Ticket
+}
+
+func (e *SalesDetailEntry) TicketName() string {
+	if e.Ticket == nil {
+		return ""
+	}
+	return e.Ticket.TicketName
+}
+
+// SalesDetails 给 SalesDetailReq 的响应补全门票信息
+func (c *Catalog) SalesDetails(list []*SalesDetailResponse) []*SalesDetailEntry {
+	out := make([]*SalesDetailEntry, 0, len(list))
+	for _, item := range list {
+		t, _ := c.Ticket(item.TicketId)
+		out = append(out, &SalesDetailEntry{SalesDetailResponse: item, Ticket: t})
+	}
+	return out
+}
+
+// ReportTicketEntry 补全门票信息的票务类型统计
+type ReportTicketEntry struct {
+	*report.TicketListData
+	Ticket *Ticket
+}
+
+// Name 优先使用响应中的名称，为空时使用目录中的名称
+func (e *ReportTicketEntry) Name() string {
+	if e.TicketName != "" || e.Ticket == nil {
+		return e.TicketName
+	}
+	return e.Ticket.TicketName
+}
+
+// ReportTickets 给 report.TicketListReq 的响应补全门票信息
+func (c *Catalog) ReportTickets(list []*report.TicketListData) []*ReportTicketEntry {
+	out := make([]*ReportTicketEntry, 0, len(list))
+	for _, item := range list {
+		t, _ := c.Ticket(item.TicketId)
+		out = append(out, &ReportTicketEntry{TicketListData: item, Ticket: t})
+	}
+	return out
+}
+
+// HotEntry 补全景区信息的热门景区数据。HotResponse.Lid 为景区 id 而非票 id，
+// LandName 来自 RegisterLand，Tickets 来自 LoadLids
+type HotEntry struct {
+	*order.HotResponse
+	LandName string
+	Tickets  []*Ticket
+}
+
+// Hot 给 order.HotReq 的响应补全景区名称与门票
+func (c *Catalog) Hot(list []*order.HotResponse) []*HotEntry {
+	out := make([]*HotEntry, 0, len(list))
+	for _, item := range list {
+		out = append(out, &HotEntry{HotResponse: item, LandName: c.LandName(item.Lid), Tickets: c.TicketsByLid(item.Lid)})
+	}
+	return out
+}
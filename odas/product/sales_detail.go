@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"github.com/piaofutong/odas-sdk/odas"
 	"net/http"
+	"time"
 )
 
 type SalesDetailOptions struct {
@@ -63,6 +64,10 @@ type AmountTrend struct {
 	TicketCount int    `json:"ticketCount"`
 }
 
+func (a AmountTrend) Bucket(day time.Time) (odas.Bucket, error) {
+	return odas.ParseBucket(a.TimeRange, day)
+}
+
 type ChannelList struct {
 	ChannelName string  `json:"channelName"`
 	TicketCount int     `json:"ticketCount"`
@@ -3,6 +3,7 @@ package report
 import (
 	"fmt"
 	"github.com/piaofutong/odas-sdk/odas"
+	"time"
 )
 
 type TerminalPassSummaryOptions struct {
@@ -59,3 +60,7 @@ type TerminalPassList struct {
 	Date string `json:"date"`
 	TerminalPassTotal
 }
+
+func (l TerminalPassList) Bucket(day time.Time) (odas.Bucket, error) {
+	return odas.ParseBucket(l.Date, day)
+}
@@ -3,6 +3,7 @@ package report
 import (
 	"fmt"
 	"github.com/piaofutong/odas-sdk/odas"
+	"time"
 )
 
 // VerifiedSummaryHourReq 验证订单小时数据
@@ -30,3 +31,7 @@ type VerifiedSummaryHourList struct {
 	Hour string `json:"hour"`
 	VerifiedSummaryResponse
 }
+
+func (l VerifiedSummaryHourList) Bucket(day time.Time) (odas.Bucket, error) {
+	return odas.ParseBucket(l.Hour, day)
+}
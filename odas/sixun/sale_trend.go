@@ -2,6 +2,7 @@ package sixun
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/piaofutong/odas-sdk/odas"
 )
@@ -29,6 +30,14 @@ type SaleTrendListItem struct {
 	FormatTime string `json:"formatTime"`
 }
 
+// Bucket 优先使用 FormatTime，缺省时解析 Time
+func (s SaleTrendListItem) Bucket(day time.Time) (odas.Bucket, error) {
+	if s.FormatTime != "" {
+		return odas.ParseBucket(s.FormatTime, day)
+	}
+	return odas.ParseBucketInt(s.Time, day)
+}
+
 type SaleTrendResponse struct {
 	List []*SaleTrendListItem `json:"list"`
 }
@@ -0,0 +1,154 @@
+package tourist
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/piaofutong/odas-sdk/odas"
+)
+
+// aggregateConcurrency 多景区/多统计组并发请求的上限
+const aggregateConcurrency = 8
+
+// SiteFlow 单个景区或统计组的出入园数据，Err 不为空表示该站点请求失败
+type SiteFlow struct {
+	Key   string
+	Total InoutTotal
+	List  []*InoutList
+	Err   error
+}
+
+// AggregateFlow 多个站点合并后的出入园数据，List 按时间对齐求和，Sites 保留各站点明细
+type AggregateFlow struct {
+	Total  InoutTotal
+	List   []*InoutList
+	Sites  []*SiteFlow
+	Failed int
+}
+
+// FlowBySids 并发查询多个景区的出入园数据并合并。
+// 部分景区失败时仍返回成功部分的合并结果，失败明细见 Sites；全部失败时返回错误。
+func FlowBySids(iam *odas.IAM, sids []string, opts ...odas.Option) (*AggregateFlow, error) {
+	day := time.Now().In(odas.Shanghai)
+	return aggregateFlow(sids, day, func(sid string) (InoutTotal, []*InoutList, error) {
+		var r FlowBySidResponse
+		err := iam.Do(NewFlowBySidReq(sid), &r, opts...)
+		return r.Total, r.List, err
+	})
+}
+
+// FlowByGroups 并发查询多个统计组在 date 当天的出入园数据并合并，失败处理同 FlowBySids
+func FlowByGroups(iam *odas.IAM, gids []int, date string, opts ...odas.Option) (*AggregateFlow, error) {
+	day, err := time.ParseInLocation("2006-01-02", date, odas.Shanghai)
+	if err != nil {
+		return nil, fmt.Errorf("invalid date %q", date)
+	}
+	keys := make([]string, len(gids))
+	for i, gid := range gids {
+		keys[i] = strconv.Itoa(gid)
+	}
+	return aggregateFlow(keys, day, func(gid string) (InoutTotal, []*InoutList, error) {
+		var r FlowByGIdsResponse
+		err := iam.Do(NewFlowByGIdsReq(gid, date), &r, opts...)
+		return r.Total, r.List, err
+	})
+}
+
+func aggregateFlow(keys []string, day time.Time, fetch func(key string) (InoutTotal, []*InoutList, error)) (*AggregateFlow, error) {
+	sites := make([]*SiteFlow, len(keys))
+	sem := make(chan struct{}, aggregateConcurrency)
+	var wg sync.WaitGroup
+	for i, key := range keys {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, key string) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			site := &SiteFlow{Key: key}
+			site.Total, site.List, site.Err = fetch(key)
+			if site.Err != nil {
+				site.Err = fmt.Errorf("%s: %w", key, site.Err)
+			}
+			sites[i] = site
+		}(i, key)
+	}
+	wg.Wait()
+
+	result := &AggregateFlow{Sites: sites}
+	var errs []error
+	var lists [][]*InoutList
+	for _, site := range sites {
+		if site.Err != nil {
+			result.Failed++
+			errs = append(errs, site.Err)
+			continue
+		}
+		result.Total.In += site.Total.In
+		result.Total.Out += site.Total.Out
+		result.Total.Hold += site.Total.Hold
+		lists = append(lists, site.List)
+	}
+	if len(keys) > 0 && result.Failed == len(keys) {
+		return nil, errors.Join(errs...)
+	}
+	result.List = mergeInoutLists(lists, day)
+	return result, nil
+}
+
+// mergeInoutLists 按 Bucket(day).Time 对齐求和，不同站点的时间格式不同也能对齐，Time 取最先出现的原始值；
+// 结果按时间排序，无法解析的时间键按原始字符串排在最后
+func mergeInoutLists(lists [][]*InoutList, day time.Time) []*InoutList {
+	type row struct {
+		*InoutList
+		at     time.Time
+		parsed bool
+	}
+	byTime := make(map[int64]*row)
+	byRaw := make(map[string]*row)
+	var rows []*row
+	for _, list := range lists {
+		for _, item := range list {
+			if item == nil {
+				continue
+			}
+			var r *row
+			var ok bool
+			b, err := item.Bucket(day)
+			if err == nil {
+				if r, ok = byTime[b.Time.UnixNano()]; !ok {
+					r = &row{InoutList: &InoutList{Time: item.Time}, at: b.Time, parsed: true}
+					byTime[b.Time.UnixNano()] = r
+				}
+			} else if r, ok = byRaw[item.Time]; !ok {
+				r = &row{InoutList: &InoutList{Time: item.Time}}
+				byRaw[item.Time] = r
+			}
+			if !ok {
+				rows = append(rows, r)
+			}
+			r.In += item.In
+			r.Out += item.Out
+		}
+	}
+	sort.SliceStable(rows, func(i, j int) bool {
+		a, b := rows[i], rows[j]
+		if a.parsed != b.parsed {
+			return a.parsed
+		}
+		if a.parsed {
+			return a.at.Before(b.at)
+		}
+		return a.Time < b.Time
+	})
+	out := make([]*InoutList, len(rows))
+	for i, r := range rows {
+		out[i] = r.InoutList
+	}
+	return out
+}
@@ -0,0 +1,211 @@
+package tourist
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+type AlertOptions struct {
+	Margin       int
+	MarginRate   float64
+	OnBandChange func(change *BandChange)
+	OnUpperLimit func(event *UpperLimitEvent)
+}
+
+type AlertOption func(options *AlertOptions)
+
+// WithAlertHysteresis 离开当前档位或解除超限需越过阈值的人数
+func WithAlertHysteresis(margin int) AlertOption {
+	return func(options *AlertOptions) {
+		options.Margin = margin
+	}
+}
+
+// WithAlertHysteresisRate 按承载量比例设置回差，如 0.02 表示承载量的 2%
+func WithAlertHysteresisRate(rate float64) AlertOption {
+	return func(options *AlertOptions) {
+		options.MarginRate = rate
+	}
+}
+
+func WithOnBandChange(fn func(change *BandChange)) AlertOption {
+	return func(options *AlertOptions) {
+		options.OnBandChange = fn
+	}
+}
+
+func WithOnUpperLimit(fn func(event *UpperLimitEvent)) AlertOption {
+	return func(options *AlertOptions) {
+		options.OnUpperLimit = fn
+	}
+}
+
+// BandChange 在园人数所在档位变化，From 为空表示首次分档或此前不在任何档位
+type BandChange struct {
+	GroupId int
+	Hold    int
+	From    *InoutGroupConfig
+	To      *InoutGroupConfig
+}
+
+// UpperLimitEvent 超过或回落到瞬时承载上限
+type UpperLimitEvent struct {
+	GroupId    int
+	Hold       int
+	UpperLimit int
+	Exceeded   bool
+}
+
+// AlertState 统计组当前告警状态
+type AlertState struct {
+	GroupId  int
+	Hold     int
+	Band     *InoutGroupConfig
+	Exceeded bool
+	// Utilization 在园人数 / 承载量，未配置承载量时为 0
+	Utilization float64
+}
+
+type alertGroup struct {
+	group  *GroupListResponse
+	bands  []*InoutGroupConfig
+	margin int
+	state  *AlertState
+}
+
+// AlertEvaluator 根据统计组配置的档位与瞬时承载上限对在园人数分级
+type AlertEvaluator struct {
+	// dispatch 串行化评估与回调，回调按评估顺序送达
+	dispatch sync.Mutex
+	mutex    sync.Mutex
+	options  *AlertOptions
+	groups   map[int]*alertGroup
+}
+
+func NewAlertEvaluator(groups []*GroupListResponse, opt ...AlertOption) *AlertEvaluator {
+	options := &AlertOptions{}
+	for _, p := range opt {
+		p(options)
+	}
+	e := &AlertEvaluator{
+		options: options,
+		groups:  make(map[int]*alertGroup, len(groups)),
+	}
+	for _, g := range groups {
+		bands := make([]*InoutGroupConfig, 0, len(g.Config))
+		for _, c := range g.Config {
+			if c != nil {
+				bands = append(bands, c)
+			}
+		}
+		sort.SliceStable(bands, func(i, j int) bool { return bands[i].Min < bands[j].Min })
+		margin := options.Margin
+		if options.MarginRate > 0 {
+			base := g.Capacity
+			if base == 0 {
+				base = g.UpperLimit
+			}
+			margin = int(math.Ceil(options.MarginRate * float64(base)))
+		}
+		e.groups[g.Id] = &alertGroup{group: g, bands: bands, margin: margin}
+	}
+	return e
+}
+
+// Evaluate 使用统计组的最新在园人数更新状态，并触发档位变化与超限回调；未知统计组返回 nil。
+// 并发评估按顺序执行，回调返回后才开始下一次评估，回调中不能再调用 Evaluate
+func (e *AlertEvaluator) Evaluate(gid int, hold int) *AlertState {
+	e.dispatch.Lock()
+	defer e.dispatch.Unlock()
+	e.mutex.Lock()
+	g, ok := e.groups[gid]
+	if !ok {
+		e.mutex.Unlock()
+		return nil
+	}
+	prev := g.state
+	next := &AlertState{GroupId: gid, Hold: hold}
+	if g.group.Capacity > 0 {
+		next.Utilization = float64(hold) / float64(g.group.Capacity)
+	}
+	next.Band = g.classify(prev, hold)
+	next.Exceeded = g.exceeded(prev, hold)
+	g.state = next
+	e.mutex.Unlock()
+
+	// 首次评估不在任何档位时不触发
+	if e.options.OnBandChange != nil && ((prev == nil && next.Band != nil) || (prev != nil && prev.Band != next.Band)) {
+		var from *InoutGroupConfig
+		if prev != nil {
+			from = prev.Band
+		}
+		e.options.OnBandChange(&BandChange{GroupId: gid, Hold: hold, From: from, To: next.Band})
+	}
+	if e.options.OnUpperLimit != nil && g.group.UpperLimit > 0 {
+		wasExceeded := prev != nil && prev.Exceeded
+		if wasExceeded != next.Exceeded {
+			e.options.OnUpperLimit(&UpperLimitEvent{
+				GroupId:    gid,
+				Hold:       hold,
+				UpperLimit: g.group.UpperLimit,
+				Exceeded:   next.Exceeded,
+			})
+		}
+	}
+	return next
+}
+
+// EvaluateFlow 使用 FlowByGIdsResponse 的在园人数评估单个统计组
+func (e *AlertEvaluator) EvaluateFlow(gid int, r *FlowByGIdsResponse) *AlertState {
+	return e.Evaluate(gid, r.Total.Hold)
+}
+
+// EvaluateAggregate 使用 FlowByGroups 结果中该统计组自身的在园人数评估，而非多组合计；
+// 结果中没有该统计组或其请求失败时返回 nil
+func (e *AlertEvaluator) EvaluateAggregate(gid int, flow *AggregateFlow) *AlertState {
+	key := strconv.Itoa(gid)
+	for _, site := range flow.Sites {
+		if site != nil && site.Key == key && site.Err == nil {
+			return e.Evaluate(gid, site.Total.Hold)
+		}
+	}
+	return nil
+}
+
+// State 返回统计组最近一次评估结果
+func (e *AlertEvaluator) State(gid int) *AlertState {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	if g, ok := e.groups[gid]; ok {
+		return g.state
+	}
+	return nil
+}
+
+func (g *alertGroup) classify(prev *AlertState, hold int) *InoutGroupConfig {
+	if prev != nil && prev.Band != nil {
+		// 仍在当前档位的回差范围内则保持不变
+		if hold >= prev.Band.Min-g.margin && hold <= prev.Band.Max+g.margin {
+			return prev.Band
+		}
+	}
+	for _, b := range g.bands {
+		if hold >= b.Min && hold <= b.Max {
+			return b
+		}
+	}
+	return nil
+}
+
+func (g *alertGroup) exceeded(prev *AlertState, hold int) bool {
+	limit := g.group.UpperLimit
+	if limit <= 0 {
+		return false
+	}
+	if prev != nil && prev.Exceeded {
+		return hold >= limit-g.margin
+	}
+	return hold >= limit
+}
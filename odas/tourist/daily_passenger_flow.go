@@ -3,6 +3,7 @@ package tourist
 import (
 	"fmt"
 	"strconv"
+	"time"
 
 	"github.com/piaofutong/odas-sdk/odas"
 )
@@ -36,3 +37,7 @@ type PassengerFlowByDateList struct {
 	Count int `json:"count"`
 	Date  int `json:"date"`
 }
+
+func (p PassengerFlowByDateList) Bucket(day time.Time) (odas.Bucket, error) {
+	return odas.ParseBucketInt(p.Date, day)
+}
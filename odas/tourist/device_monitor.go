@@ -0,0 +1,226 @@
+package tourist
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/piaofutong/odas-sdk/odas"
+)
+
+type DeviceIssue string
+
+const (
+	DeviceSilent      DeviceIssue = "silent"       // 营业时间内无出入记录
+	DeviceImbalance   DeviceIssue = "imbalance"    // 入园与出园数量严重失衡
+	DeviceSpike       DeviceIssue = "spike"        // 相对自身历史突增
+	DevicePeerOutlier DeviceIssue = "peer_outlier" // 远高于同组其他闸机，疑似重复计数
+)
+
+type DeviceMonitorOptions struct {
+	OpenFrom       int     // 营业开始小时，含
+	OpenTo         int     // 营业结束小时，不含
+	MinTraffic     int     // 低于该出入总数时不做失衡、突增与同组比较
+	ImbalanceRatio float64 // 入出比或出入比超过该值视为失衡，0 表示不检查
+	SpikeFactor    float64 // 超过历史均值的倍数视为突增，0 表示不检查
+	PeerFactor     float64 // 超过同组其他闸机均值的倍数视为异常，0 表示不检查
+	HistorySize    int     // 每台设备保留的历史样本数
+	MinHistory     int     // 历史样本数不足时不检查突增
+}
+
+type DeviceMonitorOption func(options *DeviceMonitorOptions)
+
+func WithDeviceOpenHours(from, to int) DeviceMonitorOption {
+	return func(options *DeviceMonitorOptions) {
+		options.OpenFrom = from
+		options.OpenTo = to
+	}
+}
+
+func WithDeviceMinTraffic(minTraffic int) DeviceMonitorOption {
+	return func(options *DeviceMonitorOptions) {
+		options.MinTraffic = minTraffic
+	}
+}
+
+func WithDeviceImbalanceRatio(ratio float64) DeviceMonitorOption {
+	return func(options *DeviceMonitorOptions) {
+		options.ImbalanceRatio = ratio
+	}
+}
+
+func WithDeviceSpikeFactor(factor float64) DeviceMonitorOption {
+	return func(options *DeviceMonitorOptions) {
+		options.SpikeFactor = factor
+	}
+}
+
+func WithDevicePeerFactor(factor float64) DeviceMonitorOption {
+	return func(options *DeviceMonitorOptions) {
+		options.PeerFactor = factor
+	}
+}
+
+func WithDeviceHistory(size, minSamples int) DeviceMonitorOption {
+	return func(options *DeviceMonitorOptions) {
+		options.HistorySize = size
+		options.MinHistory = minSamples
+	}
+}
+
+type DeviceStatus struct {
+	Device      string
+	GroupId     int
+	In          int
+	Out         int
+	HistoryMean float64
+	PeerMean    float64
+	Issues      []DeviceIssue
+}
+
+type DeviceReport struct {
+	Time    time.Time
+	Devices []*DeviceStatus
+	Flagged int
+}
+
+// DeviceMonitor 对 FlowByDeviceResponse 中的闸机做健康检查，需按相同时间窗口周期性调用 Check
+type DeviceMonitor struct {
+	mutex   sync.Mutex
+	options *DeviceMonitorOptions
+	groupOf map[string]int
+	gates   map[int][]string
+	history map[string][]int
+}
+
+func NewDeviceMonitor(groups []*GroupListResponse, opt ...DeviceMonitorOption) *DeviceMonitor {
+	options := &DeviceMonitorOptions{
+		OpenFrom:       8,
+		OpenTo:         18,
+		MinTraffic:     20,
+		ImbalanceRatio: 3,
+		SpikeFactor:    3,
+		PeerFactor:     3,
+		HistorySize:    48,
+		MinHistory:     3,
+	}
+	for _, p := range opt {
+		p(options)
+	}
+	m := &DeviceMonitor{
+		options: options,
+		groupOf: make(map[string]int),
+		gates:   make(map[int][]string),
+		history: make(map[string][]int),
+	}
+	for _, g := range groups {
+		for _, gate := range g.Gates {
+			m.groupOf[gate] = g.Id
+			m.gates[g.Id] = append(m.gates[g.Id], gate)
+		}
+	}
+	return m
+}
+
+// Check 评估一次设备快照并记入历史；统计组中配置但未出现在快照里的闸机按零流量处理
+func (m *DeviceMonitor) Check(at time.Time, r FlowByDeviceResponse) *DeviceReport {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	traffic := make(map[string][2]int, len(r))
+	for device, stat := range r {
+		if stat != nil {
+			traffic[device] = [2]int{stat.In, stat.Out}
+		} else {
+			traffic[device] = [2]int{}
+		}
+	}
+	for device := range m.groupOf {
+		if _, ok := traffic[device]; !ok {
+			traffic[device] = [2]int{}
+		}
+	}
+
+	devices := make([]string, 0, len(traffic))
+	for device := range traffic {
+		devices = append(devices, device)
+	}
+	sort.Strings(devices)
+
+	report := &DeviceReport{Time: at}
+	for _, device := range devices {
+		status := &DeviceStatus{Device: device, GroupId: m.groupOf[device], In: traffic[device][0], Out: traffic[device][1]}
+		status.HistoryMean = meanOf(m.history[device])
+		status.PeerMean = m.peerMean(device, traffic)
+		status.Issues = m.evaluate(at, status, len(m.history[device]))
+		if len(status.Issues) > 0 {
+			report.Flagged++
+		}
+		report.Devices = append(report.Devices, status)
+	}
+	for _, device := range devices {
+		h := append(m.history[device], traffic[device][0]+traffic[device][1])
+		if len(h) > m.options.HistorySize {
+			h = h[len(h)-m.options.HistorySize:]
+		}
+		m.history[device] = h
+	}
+	return report
+}
+
+func (m *DeviceMonitor) evaluate(at time.Time, s *DeviceStatus, samples int) []DeviceIssue {
+	o := m.options
+	total := s.In + s.Out
+	var issues []DeviceIssue
+	if hour := at.In(odas.Shanghai).Hour(); total == 0 && hour >= o.OpenFrom && hour < o.OpenTo {
+		issues = append(issues, DeviceSilent)
+	}
+	if total < o.MinTraffic || total == 0 {
+		return issues
+	}
+	if o.ImbalanceRatio > 0 {
+		hi, lo := max(s.In, s.Out), min(s.In, s.Out)
+		if float64(hi) >= o.ImbalanceRatio*float64(max(lo, 1)) {
+			issues = append(issues, DeviceImbalance)
+		}
+	}
+	if o.SpikeFactor > 0 && samples >= o.MinHistory && s.HistoryMean > 0 && float64(total) > o.SpikeFactor*s.HistoryMean {
+		issues = append(issues, DeviceSpike)
+	}
+	if o.PeerFactor > 0 && s.PeerMean > 0 && float64(total) > o.PeerFactor*s.PeerMean {
+		issues = append(issues, DevicePeerOutlier)
+	}
+	return issues
+}
+
+// peerMean 同组其他闸机的平均出入总数，设备不属于任何统计组时为 0
+func (m *DeviceMonitor) peerMean(device string, traffic map[string][2]int) float64 {
+	gid, ok := m.groupOf[device]
+	if !ok {
+		return 0
+	}
+	var sum, n int
+	for _, peer := range m.gates[gid] {
+		if peer == device {
+			continue
+		}
+		t := traffic[peer]
+		sum += t[0] + t[1]
+		n++
+	}
+	if n == 0 {
+		return 0
+	}
+	return float64(sum) / float64(n)
+}
+
+func meanOf(values []int) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	var sum int
+	for _, v := range values {
+		sum += v
+	}
+	return float64(sum) / float64(len(values))
+}
@@ -2,8 +2,10 @@ package tourist
 
 import (
 	"fmt"
+	"github.com/piaofutong/odas-sdk/odas"
 	"net/http"
 	"net/url"
+	"time"
 )
 
 // FlowByGIdsReq 根据gids查询出入园数据
@@ -62,3 +64,7 @@ type InoutList struct {
 	In   int    `json:"in"`
 	Out  int    `json:"out"`
 }
+
+func (l InoutList) Bucket(day time.Time) (odas.Bucket, error) {
+	return odas.ParseBucket(l.Time, day)
+}
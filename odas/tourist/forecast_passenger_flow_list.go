@@ -2,9 +2,11 @@ package tourist
 
 import (
 	"fmt"
+	"github.com/piaofutong/odas-sdk/odas"
 	"net/http"
 	"net/url"
 	"strconv"
+	"time"
 )
 
 // ForecastPassengerFlowListReq 预测客流每日以及汇总数据数据
@@ -85,3 +87,7 @@ type FlowForecastDetail struct {
 	TimeRange string `json:"timeRange"`
 	Count     int    `json:"count"`
 }
+
+func (f FlowForecastDetail) Bucket(day time.Time) (odas.Bucket, error) {
+	return odas.ParseBucket(f.TimeRange, day)
+}
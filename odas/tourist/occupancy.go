@@ -0,0 +1,101 @@
+package tourist
+
+import (
+	"sort"
+	"time"
+
+	"github.com/piaofutong/odas-sdk/odas"
+)
+
+type OccupancyOptions struct {
+	Capacity int
+	Initial  int
+}
+
+type OccupancyOption func(options *OccupancyOptions)
+
+// WithOccupancyCapacity 以承载量计算利用率，一般取 GroupListResponse.Capacity
+func WithOccupancyCapacity(capacity int) OccupancyOption {
+	return func(options *OccupancyOptions) {
+		options.Capacity = capacity
+	}
+}
+
+// WithOccupancyInitial 首个时间桶之前已在园的人数
+func WithOccupancyInitial(hold int) OccupancyOption {
+	return func(options *OccupancyOptions) {
+		options.Initial = hold
+	}
+}
+
+type OccupancyPoint struct {
+	Bucket    odas.Bucket
+	In        int
+	Out       int
+	Occupancy int // 时间桶结束时的在园人数
+	// Utilization 在园人数 / 承载量，未设置承载量时为 0
+	Utilization float64
+}
+
+type OccupancyReport struct {
+	Points          []*OccupancyPoint
+	Capacity        int
+	TotalIn         int
+	TotalOut        int
+	Peak            int
+	PeakTime        time.Time
+	PeakUtilization float64
+	// AverageDwell 按 Little 定律估算的平均停留时长：在园人数对时间的积分 / 入园总数
+	AverageDwell time.Duration
+}
+
+// AnalyzeOccupancy 根据分时出入园数据(InoutSummaryResponse、FlowBySidResponse、FlowByGIdsResponse 的 List)
+// 计算逐时在园人数、峰值及平均停留时长；day 用于解析只有时分的时间键
+func AnalyzeOccupancy(list []*InoutList, day time.Time, opt ...OccupancyOption) (*OccupancyReport, error) {
+	options := &OccupancyOptions{}
+	for _, p := range opt {
+		p(options)
+	}
+
+	points := make([]*OccupancyPoint, 0, len(list))
+	for _, item := range list {
+		b, err := item.Bucket(day)
+		if err != nil {
+			return nil, err
+		}
+		points = append(points, &OccupancyPoint{Bucket: b, In: item.In, Out: item.Out})
+	}
+	sort.SliceStable(points, func(i, j int) bool { return points[i].Bucket.Time.Before(points[j].Bucket.Time) })
+
+	report := &OccupancyReport{Points: points, Capacity: options.Capacity}
+	occupancy := options.Initial
+	var area float64 // 人·秒
+	for _, p := range points {
+		before := occupancy
+		occupancy += p.In - p.Out
+		if occupancy < 0 {
+			// 出园漏计或跨天离园时不出现负数
+			occupancy = 0
+		}
+		p.Occupancy = occupancy
+		if options.Capacity > 0 {
+			p.Utilization = float64(occupancy) / float64(options.Capacity)
+		}
+		report.TotalIn += p.In
+		report.TotalOut += p.Out
+		if occupancy > report.Peak || report.PeakTime.IsZero() {
+			report.Peak = occupancy
+			report.PeakTime = p.Bucket.Time
+			report.PeakUtilization = p.Utilization
+		}
+		area += float64(before+occupancy) / 2 * bucketDuration(p.Bucket).Seconds()
+	}
+	if report.TotalIn > 0 {
+		report.AverageDwell = time.Duration(area / float64(report.TotalIn) * float64(time.Second))
+	}
+	return report, nil
+}
+
+func bucketDuration(b odas.Bucket) time.Duration {
+	return b.Next().Time.Sub(b.Time)
+}
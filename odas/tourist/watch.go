@@ -0,0 +1,99 @@
+package tourist
+
+import (
+	"context"
+	"time"
+
+	"github.com/piaofutong/odas-sdk/odas"
+)
+
+const (
+	// watchMaxBackoff 请求失败时轮询间隔的上限
+	watchMaxBackoff = time.Minute
+	// watchDefaultInterval interval 不大于 0 时使用的轮询间隔
+	watchDefaultInterval = 30 * time.Second
+)
+
+// WatchEvent 出入园增量事件，In/Out 为自上次快照以来的新增人数，Total 为当前累计
+type WatchEvent struct {
+	Time  time.Time
+	In    int
+	Out   int
+	Hold  int
+	Total InoutTotal
+	Err   error
+}
+
+// Watch 按 interval 轮询统计组出入园数据，仅在数据变化时推送增量事件。
+// 首个事件的增量为当日累计值；跨天计数清零时按新的一天重新计算。
+// 请求失败时推送带 Err 的事件并按指数退避重试，ctx 结束后关闭通道。
+// interval 不大于 0 时按 watchDefaultInterval 轮询
+func Watch(ctx context.Context, iam *odas.IAM, gids string, interval time.Duration, opts ...odas.Option) <-chan *WatchEvent {
+	if interval <= 0 {
+		interval = watchDefaultInterval
+	}
+	events := make(chan *WatchEvent)
+	go func() {
+		defer close(events)
+		var last *InoutTotal
+		wait := time.Duration(0)
+		failures := 0
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(wait):
+			}
+
+			now := time.Now().In(odas.Shanghai)
+			var r FlowByGIdsResponse
+			err := iam.Do(NewFlowByGIdsReq(gids, now.Format("2006-01-02")), &r, opts...)
+			var event *WatchEvent
+			if err != nil {
+				failures++
+				wait = backoff(interval, failures)
+				event = &WatchEvent{Time: now, Err: err}
+			} else {
+				failures = 0
+				wait = interval
+				event = diffSnapshot(last, r.Total, now)
+				current := r.Total
+				last = &current
+			}
+			if event == nil {
+				continue
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case events <- event:
+			}
+		}
+	}()
+	return events
+}
+
+// diffSnapshot 计算两次快照的增量。入园或出园计数任一小于上次快照时视为整份快照已清零，
+// 两项增量均取当前累计值
+func diffSnapshot(last *InoutTotal, current InoutTotal, now time.Time) *WatchEvent {
+	if last != nil && *last == current {
+		return nil
+	}
+	event := &WatchEvent{Time: now, In: current.In, Out: current.Out, Hold: current.Hold, Total: current}
+	if last != nil && current.In >= last.In && current.Out >= last.Out {
+		event.In = current.In - last.In
+		event.Out = current.Out - last.Out
+	}
+	return event
+}
+
+func backoff(interval time.Duration, failures int) time.Duration {
+	wait := interval
+	for i := 0; i < failures && wait < watchMaxBackoff; i++ {
+		wait *= 2
+	}
+	if wait > watchMaxBackoff && interval < watchMaxBackoff {
+		wait = watchMaxBackoff
+	}
+	return wait
+}
@@ -0,0 +1,47 @@
+package test
+
+import (
+	"github.com/piaofutong/odas-sdk/odas"
+	"github.com/piaofutong/odas-sdk/odas/order"
+	"github.com/piaofutong/odas-sdk/odas/report"
+	"github.com/piaofutong/odas-sdk/odas/sixun"
+	"github.com/piaofutong/odas-sdk/odas/tourist"
+	"testing"
+	"time"
+)
+
+func TestParseBucket(t *testing.T) {
+	day := time.Date(2024, 11, 22, 0, 0, 0, 0, odas.Shanghai)
+	cases := []struct {
+		item odas.Bucketed
+		want string
+		g    odas.Granularity
+	}{
+		{order.BookingOrderListDetail{Time: 20241101}, "2024-11-01", odas.GranularityDay},
+		{order.TeamTrend{Time: 2024110110}, "2024-11-01 10:00", odas.GranularityHour},
+		{sixun.SaleTrendListItem{Time: 202411, FormatTime: ""}, "2024-11", odas.GranularityMonth},
+		{sixun.SaleTrendListItem{Time: 1, FormatTime: "2024-11-03"}, "2024-11-03", odas.GranularityDay},
+		{tourist.InoutList{Time: "09:00"}, "2024-11-22 09:00", odas.GranularityHour},
+		{tourist.InoutList{Time: "2024-11-20 13:00:00"}, "2024-11-20 13:00", odas.GranularityHour},
+		{tourist.FlowForecastDetail{TimeRange: "10:00-11:00"}, "2024-11-22 10:00", odas.GranularityHour},
+		{report.VerifiedSummaryHourList{Hour: "8"}, "2024-11-22 08:00", odas.GranularityHour},
+	}
+	for _, c := range cases {
+		b, err := c.item.Bucket(day)
+		if err != nil {
+			t.Fatalf("%+v: %v", c.item, err)
+		}
+		if b.String() != c.want || b.Granularity != c.g {
+			t.Errorf("%+v: got %s (%s), want %s (%s)", c.item, b, b.Granularity, c.want, c.g)
+		}
+		if b.Time.Location() != odas.Shanghai {
+			t.Errorf("%+v: bucket not in Asia/Shanghai", c.item)
+		}
+	}
+}
+
+func TestParseBucket_ClockWithoutDay(t *testing.T) {
+	if _, err := odas.ParseBucket("10:00", time.Time{}); err == nil {
+		t.Fatal("expected error for clock key without day")
+	}
+}
@@ -0,0 +1,94 @@
+package test
+
+import (
+	"github.com/piaofutong/odas-sdk/odas"
+	"github.com/piaofutong/odas-sdk/odas/calendar"
+	"github.com/piaofutong/odas-sdk/odas/tourist"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCalendar_DayType(t *testing.T) {
+	cal := calendar.Default()
+	cases := map[string]calendar.DayType{
+		"2024-10-01": calendar.HolidayDay,
+		"2024-10-12": calendar.Workday, // 调休上班
+		"2024-10-13": calendar.Weekend,
+		"2024-10-08": calendar.Workday,
+		"2025-01-28": calendar.HolidayDay,
+	}
+	for date, want := range cases {
+		d, _ := time.ParseInLocation("2006-01-02", date, odas.Shanghai)
+		if got := cal.DayType(d); got != want {
+			t.Errorf("%s: got %s, want %s", date, got, want)
+		}
+	}
+	tag, err := cal.Tag(tourist.PassengerFlowByDateList{Date: 20240929}, time.Time{})
+	if err != nil || tag != calendar.Workday {
+		t.Errorf("tag = %s, %v", tag, err)
+	}
+}
+
+func TestCalendar_Req(t *testing.T) {
+	cal := calendar.Default()
+	req, err := cal.Req(calendar.NationalDay, 2024, odas.Req{DateRangeReq: odas.DateRangeReq{Sid: sid}, Lid: lid})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if req.Start != "2024-10-01" || req.End != "2024-10-07" || req.Sid != sid || req.Lid != lid {
+		t.Errorf("unexpected req: %+v", req)
+	}
+	compare, err := cal.CompareReq(calendar.NationalDay, 2024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if compare.CompareStart != "2023-09-29" || compare.CompareEnd != "2023-10-06" {
+		t.Errorf("unexpected compare: %+v", compare)
+	}
+	if _, err = cal.Lookup(calendar.NationalDay, 2030); err == nil {
+		t.Error("expected missing schedule error")
+	}
+}
+
+func TestCalendar_Load(t *testing.T) {
+	cal := calendar.New()
+	err := cal.Load(strings.NewReader(`[{"name":"nationalDay","year":2027,"start":"2027-10-01","end":"2027-10-07","workdays":["2027-09-26"]}]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := cal.Lookup(calendar.NationalDay, 2027)
+	if err != nil || p.Days() != 7 {
+		t.Fatalf("unexpected period: %+v, %v", p, err)
+	}
+	if got := cal.DayType(p.Workdays[0]); got != calendar.Workday {
+		t.Errorf("makeup workday = %s", got)
+	}
+}
+
+func TestCalendar_SharedDates(t *testing.T) {
+	cal := calendar.New()
+	for _, name := range []string{"midAutumn", "nationalDay"} {
+		err := cal.Load(strings.NewReader(`[{"name":"` + name + `","year":2023,"start":"2023-09-29","end":"2023-10-06","workdays":["2023-10-07","2023-10-08"]}]`))
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	day := time.Date(2023, 10, 1, 0, 0, 0, 0, odas.Shanghai)
+	if names := cal.HolidaysOf(day); len(names) != 2 || names[0] != calendar.MidAutumn || names[1] != calendar.NationalDay {
+		t.Fatalf("holidays = %v", names)
+	}
+	// 重新登记中秋节，国庆节共用的日期与调休上班日不受影响
+	if err := cal.Load(strings.NewReader(`[{"name":"midAutumn","year":2023,"start":"2023-09-29","end":"2023-09-30"}]`)); err != nil {
+		t.Fatal(err)
+	}
+	if name, ok := cal.HolidayOf(day); !ok || name != calendar.NationalDay {
+		t.Errorf("holiday of %s = %s", day.Format("2006-01-02"), name)
+	}
+	if got := cal.DayType(time.Date(2023, 10, 7, 0, 0, 0, 0, odas.Shanghai)); got != calendar.Workday {
+		t.Errorf("makeup workday = %s", got)
+	}
+	if names := cal.HolidaysOf(time.Date(2023, 9, 29, 0, 0, 0, 0, odas.Shanghai)); len(names) != 2 {
+		t.Errorf("2023-09-29 holidays = %v", names)
+	}
+}
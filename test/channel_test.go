@@ -1,8 +1,15 @@
 package test
 
 import (
+	"bytes"
+	"context"
+	"errors"
 	"github.com/piaofutong/odas-sdk/odas"
 	"github.com/piaofutong/odas-sdk/odas/channel"
+	"math"
+	"net/http"
+	"strings"
+	"sync/atomic"
 	"testing"
 )
 
@@ -77,3 +84,266 @@ func TestService_OrderSecondaryChannel(t *testing.T) {
 		t.Fatal(err)
 	}
 }
+
+func TestTree_LazyDrillDown(t *testing.T) {
+	var fail atomic.Bool
+	iam, client := newFakeIAM(func(req *http.Request) (any, error) {
+		q := req.URL.Query()
+		switch strings.TrimPrefix(req.URL.Path, "/v4/channel/") {
+		case "orderFullChannel":
+			return `{"total":{"orderCount":10,"ticketCount":20,"amount":1000},"list":[
+				{"channelClassId":1,"channelClassName":"分销","orderCount":4,"ticketCount":8,"amount":400},
+				{"channelClassId":2,"channelClassName":"自营","orderCount":6,"ticketCount":12,"amount":600}]}`, nil
+		case "orderSecondaryChannel":
+			if fail.Load() {
+				return nil, errors.New("boom")
+			}
+			if q.Get("channelClassId") != "1" {
+				return nil, errors.New("unexpected class " + q.Get("channelClassId"))
+			}
+			return `{"list":[{"channelClassId":11,"channelClassName":"OTA","orderCount":3,"ticketCount":6,"amount":300}]}`, nil
+		case "statDistributorSummary":
+			if q.Get("channelClassId") != "" || q.Get("channelId") != "" {
+				return nil, errors.New("distributors are not filtered by channel")
+			}
+			return `[{"distributor_id":7,"distributor_name":"携程","date":"2024-05-01","order_count":1,"ticket_count":2,"amount":100},
+				{"distributor_id":8,"distributor_name":"美团","date":"2024-05-01","order_count":1,"ticket_count":1,"amount":50},
+				{"distributor_id":7,"distributor_name":"携程","date":"2024-05-02","order_count":2,"ticket_count":3,"amount":250}]`, nil
+		}
+		return nil, errors.New("unexpected " + req.URL.Path)
+	})
+	ctx := context.Background()
+	tree := channel.NewTree(iam, &odas.Req{DateRangeReq: odas.DateRangeReq{Sid: sid, Start: start, End: end}}, odas.WithToken(token))
+
+	classes, err := tree.Classes(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tree.Root().Amount != 1000 || len(classes) != 2 || classes[0].Name != "自营" || classes[1].Rate != 0.4 {
+		t.Fatalf("classes = %+v", classes)
+	}
+	if len(client.calls) != 1 || classes[1].Loaded() {
+		t.Fatalf("sub-levels fetched eagerly: %d calls", len(client.calls))
+	}
+
+	fail.Store(true)
+	if _, err = classes[1].Children(ctx); err == nil || classes[1].Loaded() {
+		t.Fatal("expected uncached error")
+	}
+	fail.Store(false)
+	secondary, err := classes[1].Children(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(secondary) != 1 || secondary[0].Id != 11 || secondary[0].Name != "OTA" || secondary[0].Parent() != classes[1] ||
+		classes[1].Unattributed().Amount != 100 {
+		t.Fatalf("secondary = %+v", secondary)
+	}
+	calls := len(client.calls)
+	if children, err := secondary[0].Children(ctx); err != nil || children != nil || len(client.calls) != calls {
+		t.Fatal("secondary channels should be leaves")
+	}
+	if _, err = classes[1].Children(ctx); err != nil || len(client.calls) != calls {
+		t.Fatal("loaded children should be cached")
+	}
+
+	distributors, err := tree.Distributors(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(distributors) != 2 || distributors[0].Id != 7 || distributors[0].Amount != 350 || distributors[0].OrderCount != 3 ||
+		distributors[0].Level != channel.LevelDistributor || distributors[0].Parent() != tree.Root() || distributors[1].Rate != 0.125 {
+		t.Fatalf("distributors = %+v", distributors)
+	}
+	// 分销商不归属渠道，不影响根节点的下级与未分配部分
+	if root := tree.Root(); root.Unattributed().Amount != 0 {
+		t.Errorf("root unattributed = %+v", root.Unattributed())
+	}
+	calls = len(client.calls)
+	if _, err = tree.Distributors(ctx); err != nil || len(client.calls) != calls {
+		t.Fatal("distributors should be cached")
+	}
+}
+
+func TestTree_Expand(t *testing.T) {
+	iam, _ := newFakeIAM(func(req *http.Request) (any, error) {
+		switch strings.TrimPrefix(req.URL.Path, "/v4/channel/") {
+		case "orderFullChannel":
+			return `{"total":{"amount":300},"list":[{"channelClassId":1,"amount":100},{"channelClassId":2,"amount":200}]}`, nil
+		case "orderSecondaryChannel":
+			if req.URL.Query().Get("channelClassId") == "2" {
+				return nil, errors.New("boom")
+			}
+			return `{"list":[{"channelClassId":11,"amount":100}]}`, nil
+		case "statDistributorSummary":
+			return `[{"distributor_id":7,"distributor_name":"携程","amount":100}]`, nil
+		}
+		return nil, errors.New("unexpected " + req.URL.Path)
+	})
+	tree := channel.NewTree(iam, &odas.Req{DateRangeReq: odas.DateRangeReq{Sid: sid}}, odas.WithToken(token))
+	if err := tree.Expand(context.Background(), 2); err == nil || !strings.Contains(err.Error(), "boom") {
+		t.Fatalf("err = %v", err)
+	}
+	classes, _ := tree.Classes(context.Background())
+	if !classes[1].Loaded() || classes[0].Loaded() {
+		t.Fatal("expected class 1 loaded and class 2 not loaded")
+	}
+	if distributors, err := tree.Distributors(context.Background()); err != nil || len(distributors) != 1 {
+		t.Fatalf("distributors = %v, %v", distributors, err)
+	}
+}
+
+func TestScorecards(t *testing.T) {
+	row := func(id int, name, date string, amount int) *channel.StatDistributorSummaryResponse {
+		return &channel.StatDistributorSummaryResponse{DistributorID: id, DistributorName: name, Date: date, OrderCount: 1, TicketCount: amount / 100, Amount: amount}
+	}
+	var list []*channel.StatDistributorSummaryResponse
+	for _, date := range []string{"2024-05-01", "2024-05-02", "2024-05-03", "2024-05-04", "2024-05-05", "2024-05-06"} {
+		list = append(list, row(1, "携程", date, 1000))
+	}
+	// 美团最后 3 天停止出票
+	for _, date := range []string{"2024-05-01", "2024-05-02", "2024-05-03"} {
+		list = append(list, row(2, "美团", date, 2500))
+	}
+	// 飞猪只有两天数据，且同一天两条记录合并
+	list = append(list, row(3, "飞猪", "2024-05-05", 300), row(3, "飞猪", "2024-05-06", 300), row(3, "飞猪", "2024-05-06", 300))
+
+	report, err := channel.Scorecards(list)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Start != "2024-05-01" || report.End != "2024-05-06" || report.Total.Amount != 14400 {
+		t.Fatalf("report = %+v", report)
+	}
+	first, second, third := report.Scores[0], report.Scores[1], report.Scores[2]
+	if first.DistributorName != "美团" || second.DistributorName != "携程" || third.Rank != 3 {
+		t.Fatalf("rank = %s %s %s", first.DistributorName, second.DistributorName, third.DistributorName)
+	}
+	if second.Consistency != 1 || *second.Growth != 0 || second.AvgPrice != 100 || second.ActiveDays != 6 {
+		t.Errorf("携程 = %+v", second)
+	}
+	if first.DropOff == nil || first.DropOff.Since != "2024-05-04" || first.DropOff.Change != -1 || *first.Growth != -1 {
+		t.Errorf("美团 drop-off = %+v", first.DropOff)
+	}
+	if third.Growth != nil || third.Amount != 900 || len(third.Series().Points) != 6 {
+		t.Errorf("飞猪 = %+v", third)
+	}
+	if drops := report.DropOffs(); len(drops) != 1 || drops[0].DistributorId != 2 {
+		t.Errorf("drop-offs = %v", drops)
+	}
+
+	report.Rank(channel.RankByConsistency)
+	if report.Scores[0].DistributorId != 1 {
+		t.Errorf("consistency leader = %d", report.Scores[0].DistributorId)
+	}
+
+	previous := []*channel.StatDistributorSummaryResponse{row(3, "飞猪", "2024-04-01", 450)}
+	report, err = channel.Scorecards(list, channel.WithScorecardPrevious(previous), channel.WithScorecardRankBy(channel.RankByGrowth))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Scores[0].DistributorId != 3 || *report.Scores[0].Growth != 1 || report.Scores[2].Growth != nil {
+		t.Errorf("growth ranking = %+v", report.Scores[0])
+	}
+
+	// 两期按各自区间过滤：上期 2024-03-31 的明细不计入
+	previous = append(previous, row(3, "飞猪", "2024-03-31", 10000))
+	ranged, err := channel.Scorecards(list,
+		channel.WithScorecardDateRange("2024-05-04", "2024-05-06"),
+		channel.WithScorecardPrevious(previous),
+		channel.WithScorecardPreviousDateRange("2024-04-01", "2024-04-03"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ranged.Total.Amount != 3900 || ranged.Start != "2024-05-04" || len(ranged.Scores[0].Series().Points) != 3 {
+		t.Fatalf("ranged = %+v", ranged)
+	}
+	for _, score := range ranged.Scores {
+		if score.DistributorId == 3 && (score.Growth == nil || *score.Growth != 1) {
+			t.Errorf("飞猪 ranged growth = %v", score.Growth)
+		}
+	}
+	if _, err = channel.Scorecards(list, channel.WithScorecardDateRange("2024-05-04", "2024-05-06"), channel.WithScorecardPrevious(previous)); err == nil {
+		t.Error("expected error without previous date range")
+	}
+	if _, err = channel.PivotDistributors(list, "2024-05-06", "2024-05-01"); err == nil {
+		t.Error("expected error for start after end")
+	}
+
+	var buf bytes.Buffer
+	if err = report.WriteCSV(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(buf.String(), "rank,distributorId,distributorName,orderCount,ticketCount,amount,avgPrice,share,growth,consistency,activeDays,dropOffSince,dropOffChange\n1,3,飞猪,3,9,900,100.00,0.0625,1.0000,") {
+		t.Errorf("unexpected csv:\n%s", buf.String())
+	}
+}
+
+func TestMixShift(t *testing.T) {
+	iam, client := newFakeIAM(func(req *http.Request) (any, error) {
+		if !strings.HasSuffix(req.URL.Path, "/orderSecondaryChannel") || req.URL.Query().Get("channelClassId") != "1" {
+			return nil, errors.New("unexpected " + req.URL.String())
+		}
+		switch req.URL.Query().Get("start") {
+		case "2024-05-01":
+			return `{"total":{"orderCount":30,"ticketCount":60,"amount":1500},"list":[
+				{"channelClassId":11,"channelClassName":"携程","amount":900},
+				{"channelClassId":12,"channelClassName":"美团","amount":400},
+				{"channelClassName":"抖音","amount":100},
+				{"channelClassId":13,"channelClassName":"其他","amount":0}]}`, nil
+		case "2024-04-01":
+			return `{"total":{"amount":1000},"list":[
+				{"channelClassId":11,"channelClassName":"携程","amount":500},
+				{"channelClassName":"美团","amount":500}]}`, nil
+		}
+		return nil, errors.New("unexpected period " + req.URL.Query().Get("start"))
+	})
+	report, err := channel.MixShift(iam, &channel.MixReq{
+		Req:                 odas.Req{DateRangeReq: odas.DateRangeReq{Sid: sid, Start: "2024-05-01", End: "2024-05-31"}},
+		DateRangeCompareReq: odas.DateRangeCompareReq{CompareStart: "2024-04-01", CompareEnd: "2024-04-30"},
+		ChannelClassId:      1,
+	}, odas.WithToken(token))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(client.calls) != 2 || report.CompareStart != "2024-04-01" || report.CompareEnd != "2024-04-30" {
+		t.Fatalf("compare period = %s ~ %s", report.CompareStart, report.CompareEnd)
+	}
+	if *report.Growth != 0.5 || report.Change != 500 || len(report.Rows) != 5 {
+		t.Fatalf("report = %+v", report)
+	}
+	var (
+		contribution float64
+		other        *channel.MixRow
+	)
+	rows := make(map[string]*channel.MixRow)
+	for _, row := range report.Rows {
+		contribution += row.Contribution
+		if row.Residual {
+			other = row
+			continue
+		}
+		rows[row.ChannelName] = row
+	}
+	if math.Abs(contribution-*report.Growth) > 1e-9 {
+		t.Errorf("contributions sum to %v", contribution)
+	}
+	ctrip, meituan, douyin := rows["携程"], rows["美团"], rows["抖音"]
+	if report.Rows[0] != ctrip || ctrip.Change != 400 || *ctrip.ChangeRate != 0.8 || math.Abs(ctrip.ShareShift-0.1) > 1e-9 || *ctrip.ContributionShare != 0.8 {
+		t.Errorf("携程 = %+v", ctrip)
+	}
+	// 美团上期缺少 id，按名称关联
+	if meituan.ChannelId != 12 || meituan.Previous.Amount != 500 || *meituan.ChangeRate != -0.2 {
+		t.Errorf("美团 = %+v", meituan)
+	}
+	if douyin.ChangeRate != nil || douyin.Previous.Amount != 0 || other.Current.Amount != 100 || other.Current.OrderCount != 30 {
+		t.Errorf("抖音 = %+v, 其他 = %+v", douyin, other)
+	}
+	// 名为 "其他" 的真实渠道不与剩余行合并
+	if named := rows[channel.Other]; named == nil || named.ChannelId != 13 || named.Current.Amount != 0 || other.ChannelId != 0 {
+		t.Errorf("named = %+v, residual = %+v", named, other)
+	}
+	if gainers, losers := report.Gainers(), report.Losers(); gainers[0] != ctrip || losers[0] != meituan {
+		t.Errorf("gainers = %v, losers = %v", gainers, losers)
+	}
+}
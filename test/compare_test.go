@@ -0,0 +1,110 @@
+package test
+
+import (
+	"github.com/piaofutong/odas-sdk/odas"
+	"github.com/piaofutong/odas-sdk/odas/sixun"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestComparePeriod(t *testing.T) {
+	cases := []struct {
+		mode       odas.CompareMode
+		start, end string
+		wantStart  string
+		wantEnd    string
+	}{
+		{odas.CompareMom, "2024-10-08", "2024-10-14", "2024-10-01", "2024-10-07"},
+		{odas.CompareYoy, "2024-02-01", "2024-02-29", "2023-02-01", "2023-02-28"},
+		{odas.CompareMom, "2024-10-08 00:00:00", "2024-10-08 23:59:59", "2024-10-07 00:00:00", "2024-10-07 23:59:59"},
+		{odas.CompareMom, "2024-03-01", "2024-03-31", "2024-02-01", "2024-02-29"},
+		{odas.CompareMom, "2024-04-01 00:00:00", "2024-05-31 23:59:59", "2024-02-01 00:00:00", "2024-03-31 23:59:59"},
+	}
+	for _, c := range cases {
+		s, e, err := odas.ComparePeriod(c.start, c.end, c.mode)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s != c.wantStart || e != c.wantEnd {
+			t.Errorf("ComparePeriod(%s, %s) = %s, %s; want %s, %s", c.start, c.end, s, e, c.wantStart, c.wantEnd)
+		}
+	}
+}
+
+func TestCompare_SaleTrendYoy(t *testing.T) {
+	iam, client := newFakeIAM(func(req *http.Request) (any, error) {
+		if req.URL.Query().Get("start") == "2024-10-01" {
+			return `{"list":[{"amount":200,"orderNum":4,"formatTime":"2024-10-01"},{"amount":300,"orderNum":6,"formatTime":"2024-10-02"}]}`, nil
+		}
+		return `{"list":[{"amount":100,"orderNum":0,"formatTime":"2023-10-01"}]}`, nil
+	})
+	base := odas.Req{DateRangeReq: odas.DateRangeReq{Sid: sid}}
+	result, err := odas.Compare(iam, &odas.CompareRequest[sixun.SaleTrendResponse, *sixun.SaleTrendListItem]{
+		Start: "2024-10-01",
+		End:   "2024-10-02",
+		Mode:  odas.CompareYoy,
+		Build: func(start, end string) odas.IRequest {
+			r := base
+			r.Start, r.End = start, end
+			return sixun.NewSaleTrendReq(&r)
+		},
+		List: func(resp *sixun.SaleTrendResponse) []*sixun.SaleTrendListItem { return resp.List },
+		Time: func(item *sixun.SaleTrendListItem) (time.Time, error) {
+			return time.ParseInLocation("2006-01-02", item.FormatTime, odas.Shanghai)
+		},
+	}, odas.WithToken(token))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(client.calls) != 2 || client.calls[1].URL.Query().Get("start") != "2023-10-01" {
+		t.Fatalf("unexpected calls: %v", client.calls)
+	}
+	if len(result.Points) != 2 {
+		t.Fatalf("got %d points", len(result.Points))
+	}
+	first, second := result.Points[0], result.Points[1]
+	if !first.HasPrevious || first.Delta["amount"] != 100 || *first.Growth["amount"] != 1 {
+		t.Errorf("unexpected first point: %+v", first)
+	}
+	if first.Growth["orderNum"] != nil {
+		t.Errorf("growth against zero should be nil")
+	}
+	if second.HasPrevious || second.Delta["amount"] != 300 {
+		t.Errorf("unexpected second point: %+v", second)
+	}
+}
+
+func TestCompare_MomWholeMonth(t *testing.T) {
+	iam, client := newFakeIAM(func(req *http.Request) (any, error) {
+		if req.URL.Query().Get("start") == "2024-03-01" {
+			return `{"list":[{"amount":200,"formatTime":"2024-03-01"},{"amount":300,"formatTime":"2024-03-31"}]}`, nil
+		}
+		return `{"list":[{"amount":100,"formatTime":"2024-02-01"},{"amount":50,"formatTime":"2024-02-29"}]}`, nil
+	})
+	base := odas.Req{DateRangeReq: odas.DateRangeReq{Sid: sid}}
+	result, err := odas.Compare(iam, &odas.CompareRequest[sixun.SaleTrendResponse, *sixun.SaleTrendListItem]{
+		Start: "2024-03-01",
+		End:   "2024-03-31",
+		Mode:  odas.CompareMom,
+		Build: func(start, end string) odas.IRequest {
+			r := base
+			r.Start, r.End = start, end
+			return sixun.NewSaleTrendReq(&r)
+		},
+		List: func(resp *sixun.SaleTrendResponse) []*sixun.SaleTrendListItem { return resp.List },
+	}, odas.WithToken(token))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if q := client.calls[1].URL.Query(); q.Get("start") != "2024-02-01" || q.Get("end") != "2024-02-29" {
+		t.Fatalf("unexpected compare range: %v", q)
+	}
+	first, last := result.Points[0], result.Points[1]
+	if !first.HasPrevious || first.Delta["amount"] != 100 {
+		t.Errorf("2024-03-01 should compare with 2024-02-01: %+v", first)
+	}
+	if last.HasPrevious {
+		t.Errorf("2024-03-31 has no counterpart in February: %+v", last)
+	}
+}
@@ -0,0 +1,283 @@
+package test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"reflect"
+	"testing"
+
+	"github.com/piaofutong/odas-sdk/odas"
+	"github.com/piaofutong/odas-sdk/odas/channel"
+	"github.com/piaofutong/odas-sdk/odas/gadget"
+	"github.com/piaofutong/odas-sdk/odas/hotel"
+	"github.com/piaofutong/odas-sdk/odas/order"
+	"github.com/piaofutong/odas-sdk/odas/portrait"
+	"github.com/piaofutong/odas-sdk/odas/product"
+	"github.com/piaofutong/odas-sdk/odas/report"
+	"github.com/piaofutong/odas-sdk/odas/sixun"
+	"github.com/piaofutong/odas-sdk/odas/tourist"
+)
+
+const formContentType = "application/x-www-form-urlencoded"
+
+// contract 请求应编码出的路径、方法与参数。shared 表示与其他用例共用接口，如兼容旧写法的请求、同一请求的不同参数或独立路径尚未确认的请求
+type contract struct {
+	name        string
+	req         odas.IRequest
+	path        string
+	method      string
+	contentType string
+	params      url.Values
+	shared      bool
+}
+
+func contractReq() *odas.Req {
+	return &odas.Req{
+		DateRangeReq: odas.DateRangeReq{Sid: 3385, Start: "2024-05-01", End: "2024-05-07"},
+		Lid:          "101,102",
+	}
+}
+
+func contractDateRange() *odas.DateRangeReq {
+	return &odas.DateRangeReq{Sid: 3385, Start: "2024-05-01", End: "2024-05-07"}
+}
+
+// with 在基础查询参数上追加键值对
+func with(kv ...string) url.Values {
+	params := url.Values{"sid": {"3385"}, "start": {"2024-05-01"}, "end": {"2024-05-07"}, "lid": {"101,102"}}
+	for i := 0; i+1 < len(kv); i += 2 {
+		params.Add(kv[i], kv[i+1])
+	}
+	return params
+}
+
+// withRange 与 with 相同，但基础参数不含 lid
+func withRange(kv ...string) url.Values {
+	params := with(kv...)
+	params.Del("lid")
+	return params
+}
+
+func contracts() []contract {
+	compare := &odas.DateRangeCompareReq{CompareStart: "2023-05-01", CompareEnd: "2023-05-07"}
+	compareParams := []string{"compareStart", "2023-05-01", "compareEnd", "2023-05-07"}
+	get := func(name string, req odas.IRequest, path string, params url.Values) contract {
+		return contract{name: name, req: req, path: path, method: http.MethodGet, contentType: formContentType, params: params}
+	}
+	shared := func(c contract) contract {
+		c.shared = true
+		return c
+	}
+
+	return []contract{
+		// tourist
+		get("tourist.DailyPassengerFlow", tourist.NewDailyPassengerFlowReq(contractReq(), true),
+			"/v4/tourist/dailyPassengerFlow", with("unknown", "true")),
+		get("tourist.DailyPassengerFlowByVerify", tourist.NewDailyPassengerFlowByVerifyReq(contractReq(), false),
+			"/v4/tourist/dailyPassengerFlowByVerify", with()),
+		get("tourist.FlowByDevice", tourist.NewFlowByDeviceReq("d1,d2", 9),
+			"/v2/tourist/inout/flowByDevice", url.Values{"devices": {"d1,d2"}, "hour": {"9"}}),
+		get("tourist.FlowByGIds", tourist.NewFlowByGIdsReq("1,2", "2024-05-01"),
+			"/v2/tourist/inout/flowByGIds", url.Values{"gIds": {"1,2"}, "date": {"2024-05-01"}}),
+		get("tourist.FlowBySid", tourist.NewFlowBySidReq("3385"),
+			"/v2/tourist/inout/flowBySid", url.Values{"sid": {"3385"}}),
+		get("tourist.ForecastPassengerFlowList", tourist.NewForecastPassengerFlowListReq("2024-05-01", "2024-05-07", "101", "102", 3385, 1),
+			"/v4/tourist/forecastPassengerFlowList",
+			url.Values{"start": {"2024-05-01"}, "end": {"2024-05-07"}, "lid": {"101"}, "excludeLid": {"102"}, "sid": {"3385"}, "orderType": {"1"}}),
+		get("tourist.ForecastPassengerFlowSummary", tourist.NewForecastPassengerFlowSummaryReq("2024-05-01", "2024-05-07", "", "", 3385, 0),
+			"/v4/tourist/forecastPassengerFlowSummary", url.Values{"start": {"2024-05-01"}, "end": {"2024-05-07"}, "sid": {"3385"}}),
+		get("tourist.GroupById", tourist.NewGroupByIdReq(7),
+			"/v2/tourist/inout/groupById", url.Values{"id": {"7"}}),
+		get("tourist.GroupList", tourist.NewGroupListReq(3385),
+			"/v2/tourist/inout/groupList", url.Values{"sid": {"3385"}}),
+		get("tourist.InoutByGroupId", tourist.NewInoutByGroupId(7),
+			"/tourist/tourist/inout/flow", url.Values{"gid": {"7"}}),
+		get("tourist.SummaryByDate", tourist.NewSummaryByDateReq(tourist.WithSid(3385), tourist.WithStart("2024-05-01"), tourist.WithEnd("2024-05-07"), tourist.WithGid("7"), tourist.WithNoAmend(), tourist.WithDateType(2)),
+			"/v4/tourist/inout/summaryByDate", withRange("gid", "7", "noAmend", "true", "dateType", "2")),
+		get("tourist.SummaryByTime", tourist.NewSummaryByTimeReq(tourist.WithSid(3385), tourist.WithStart("2024-05-01"), tourist.WithEnd("2024-05-07")),
+			"/v4/tourist/inout/summaryByTime", withRange()),
+		get("tourist.Local", tourist.NewLocalReq(contractReq(), tourist.WithLocalLimit(10), tourist.WithLocalUnknown(true), tourist.WithLocalProvince("浙江省")),
+			"/v4/tourist/touristLocal", with("limit", "10", "unknown", "true", "province", "浙江省")),
+		get("tourist.LocalByTicket", tourist.NewLocalByTicketReq(contractReq(), compare, "浙江省", "杭州市", tourist.WithLocalByTicketLimit(5), tourist.WithRegionType("city")),
+			"/v4/tourist/touristLocalByTicket", with(append([]string{"limit", "5", "province", "浙江省", "city", "杭州市", "regionType", "city"}, compareParams...)...)),
+		get("tourist.LocalByVerify", tourist.NewLocalByVerifyReq(contractReq(), tourist.WithLocalProvince("浙江省")),
+			"/v4/tourist/touristLocalByVerify", with("province", "浙江省")),
+
+		// order
+		get("order.BookingOrderList", order.NewBookingOrderListReq(contractReq()),
+			"/v4/order/booking/orderList", with()),
+		get("order.BookingTeamOrder", order.NewBookingTeamOrderReq(contractReq(), compare),
+			"/v4/order/booking/teamOrder", with(compareParams...)),
+		get("order.Hot", order.NewHotReq(contractReq(), 10),
+			"/v4/order/hot", with("limit", "10")),
+		get("order.PreBookingAgeGenderDist", order.NewPreBookingAgeGenderDistReq(*contractReq()),
+			"/v4/order/preBookingAgeGenderDist", withRange()),
+		get("order.PreBookingCountryProvinceDist", order.NewPreBookingCountryProvinceDistReq(*contractReq()),
+			"/v4/order/preBookingCountryProvinceDist", withRange()),
+		shared(get("order.PreBookingByType", order.NewPreBookingByTypeReq(*contractDateRange(), order.WithLid("101,102"), order.WithExcludeLid("103"), order.WithOrderType(2)),
+			"/v4/order/preBookingSummary", with("excludeLid", "103", "orderType", "2"))),
+		get("order.PreBookingSummary", order.NewPreBookingSummaryReq(*contractDateRange(), order.WithLid("101,102")),
+			"/v4/order/preBookingSummary", with()),
+		get("order.Summary", order.NewSummaryReq(contractReq(), order.WithOrderCompare()),
+			"/v4/order/summary", with("compare", "true")),
+		get("order.ToiSummary", order.NewToiSummaryReq(contractReq()),
+			"/v4/order/toi/summary", with()),
+
+		// portrait
+		get("portrait.SexAgeSummary", portrait.NewSexAgeSummaryReq(contractReq(), portrait.WithSexAgeUnknown(true), portrait.WithSexAgeProvince("浙江省")),
+			"/v4/portrait/ageSummary", with("unknown", "true", "province", "浙江省")),
+		get("portrait.SexAgeSummary/ticket", portrait.NewSexAgeSummaryReq(contractReq(), portrait.WithSexAgeBasis(portrait.BasisTicket)),
+			"/v4/portrait/ageSummaryByTicket", with()),
+		get("portrait.SexAgeSummary/verify", portrait.NewSexAgeSummaryReq(contractReq(), portrait.WithSexAgeBasis(portrait.BasisVerify)),
+			"/v4/portrait/ageSummaryByVerify", with()),
+		get("portrait.Province", portrait.NewProvinceReq(contractReq(), compare, portrait.WithProvinceLimit(10), portrait.WithProvinceUnknown(true)),
+			"/v4/portrait/province", with(append([]string{"limit", "10", "unknown", "true"}, compareParams...)...)),
+		get("portrait.Province/verify", portrait.NewProvinceReq(contractReq(), &odas.DateRangeCompareReq{}, portrait.WithProvinceBasis(portrait.BasisVerify)),
+			"/v4/portrait/provinceByVerify", with()),
+		get("portrait.City", portrait.NewCityReq(contractReq(), compare, portrait.WithCityLimit(10), portrait.WithCityProvince("浙江省")),
+			"/v4/portrait/city", with(append([]string{"limit", "10", "province", "浙江省"}, compareParams...)...)),
+		get("portrait.City/verify", portrait.NewCityReq(contractReq(), &odas.DateRangeCompareReq{}, portrait.WithCityBasis(portrait.BasisVerify)),
+			"/v4/portrait/cityByVerify", with()),
+		get("portrait.Fellow", portrait.NewFellowReq(contractReq(), portrait.WithFellowProvince("浙江省")),
+			"/v4/portrait/fellow", with("province", "浙江省")),
+		get("portrait.Fellow/ticket", portrait.NewFellowReq(contractReq(), portrait.WithFellowBasis(portrait.BasisTicket)),
+			"/v4/portrait/fellowByTicket", with()),
+		get("portrait.PaymentMethod", portrait.NewPaymentMethodReq(contractReq(), portrait.WithPaymentMethodLimit(5), portrait.WithPaymentMethodProvince("浙江省")),
+			"/v4/portrait/paymentMethod", with("limit", "5", "province", "浙江省")),
+		get("portrait.PaymentMethod/ticket", portrait.NewPaymentMethodReq(contractReq(), portrait.WithPaymentMethodBasis(portrait.BasisTicket)),
+			"/v4/portrait/paymentMethodByTicket", with()),
+		get("portrait.BookingCountryProvinceLocationRank", portrait.NewBookingCountryProvinceLocationRankReq(contractReq()),
+			"/v4/portrait/bookingCountryProvinceLocationRank", with()),
+		get("portrait.VerifiedCountryProvinceLocationRank", portrait.NewVerifiedCountryProvinceLocationRankReq(contractReq(),
+			portrait.WithVerifiedCountryProvinceLocationRankLimit(10), portrait.WithVerifiedCountryProvinceLocationRankUnknown(true), portrait.WithVerifiedCountryProvinceLocationRankProvince("浙江省")),
+			"/v4/portrait/verifiedCountryProvinceLocationRank", with("limit", "10", "unknown", "true", "province", "浙江省")),
+		shared(get("portrait.SexAgeSummaryByTicket", portrait.NewSexAgeSummaryByTicketReq(contractReq()),
+			"/v4/portrait/ageSummaryByTicket", with())),
+		shared(get("portrait.SexAgeSummaryByVerify", portrait.NewSexAgeSummaryByVerifyReq(contractReq()),
+			"/v4/portrait/ageSummaryByVerify", with())),
+		shared(get("portrait.ProvinceByVerify", portrait.NewProvinceByVerifyReq(contractReq(), &odas.DateRangeCompareReq{}),
+			"/v4/portrait/provinceByVerify", with())),
+		shared(get("portrait.CityByVerify", portrait.NewCityByVerifyReq(contractReq(), &odas.DateRangeCompareReq{}),
+			"/v4/portrait/cityByVerify", with())),
+		shared(get("portrait.FellowByTicket", portrait.NewFellowByTicketReq(contractReq(), portrait.WithFellowByTicketProvince("浙江省")),
+			"/v4/portrait/fellowByTicket", with("province", "浙江省"))),
+		shared(get("portrait.PaymentMethodByTicket", portrait.NewPaymentMethodByTicketReq(contractReq(), portrait.WithPaymentMethodByTicketLimit(5)),
+			"/v4/portrait/paymentMethodByTicket", with("limit", "5"))),
+
+		// channel
+		get("channel.OrderChannel", channel.NewOrderChannelReq(contractReq()),
+			"/v4/channel/orderChannel", with()),
+		get("channel.OrderFullChannel", channel.NewOrderFullChannelReq(contractReq(), channel.WithLimit(10)),
+			"/v4/channel/orderFullChannel", with("limit", "10")),
+		get("channel.OrderSecondaryChannel", channel.NewOrderSecondaryChannel(contractReq(), channel.WithSecondaryChannelClassId(2), channel.WithSecondaryChannelLimit(10)),
+			"/v4/channel/orderSecondaryChannel", with("channelClassId", "2", "limit", "10")),
+		get("channel.StatDistributorSummary", channel.NewStatDistributorSummaryReq(contractReq()),
+			"/v4/channel/statDistributorSummary", with()),
+
+		// hotel
+		get("hotel.Occupancy", hotel.NewOccupancyReq(contractDateRange()),
+			"/v2/hotel/occupancy", withRange()),
+		get("hotel.RevenueReportSummary", hotel.NewRevenueReportSummary(contractDateRange(), "ROOM"),
+			"/v2/hotel/revenueReportSummary", withRange("codeCategory", "ROOM")),
+		get("hotel.RoomOrderDateList", hotel.NewRmOrderDateListReq(contractDateRange()),
+			"/v2/hotel/rmOrderDateList", withRange()),
+		get("hotel.RmSaleReportDateList", hotel.NewRmSaleReportDateListReq(contractDateRange()),
+			"/v2/hotel/rmSaleReportDateList", withRange()),
+		get("hotel.RoomSaleReportList", hotel.NewRmSaleReportListReq(contractDateRange()),
+			"/v2/hotel/rmSaleReportList", withRange()),
+
+		// product
+		get("product.Rank", product.NewRankReq(contractReq(), product.WithRankLimit(10)),
+			"/v4/product/rank", with("limit", "10")),
+		get("product.TicketList", product.NewTicketListReq(contractReq(), compare, 2, 50),
+			"/v4/product/ticketList", with(append([]string{"page", "2", "pageSize", "50"}, compareParams...)...)),
+		{name: "product.SalesDetail", req: product.NewSalesDetailReq(contractReq(), product.WithSalesDetailTicketId([]int{1, 2})),
+			path: "/v4/product/salesDetail", method: http.MethodPost, contentType: "application/json", params: url.Values{}},
+
+		// report
+		get("report.TerminalPassSummary", report.NewTerminalPassSummaryReq(contractReq(), report.WithTerminalType("gate")),
+			"/v4/report/terminalPassSummary", with("terminalType", "gate")),
+		get("report.TerminalPassSummaryGroupLid", report.NewTerminalPassSummaryGroupLidReq(contractReq()),
+			"/v4/report/terminalPassSummaryGroupLid", with()),
+		get("report.TicketList", report.NewTicketListReq(contractReq(), 10, "201"),
+			"/v4/report/ticketList", with("limit", "10", "ticketId", "201")),
+		get("report.VerifiedSummary", report.NewVerifiedSummaryReq(contractReq()),
+			"/v4/report/verifiedSummary", with()),
+		get("report.VerifiedSummaryHour", report.NewVerifiedSummaryHourReq(contractReq()),
+			"/v4/report/verifiedSummaryByHour", with()),
+
+		// sixun
+		get("sixun.SaleProductTopN", sixun.NewSaleProductTopNReq(contractReq(), sixun.WithSaleProductTopNReqLimit(10)),
+			"/v4/sixun/saleProductTopN", with("limit", "10")),
+		get("sixun.SaleShopTopN", sixun.NewSaleShopTopNReq(contractReq(), sixun.WithSaleShopTOpNReqLimit(10)),
+			"/v4/sixun/saleShopTopN", with("limit", "10")),
+		get("sixun.SaleTotalByTimeRange", sixun.NewSaleTotalByTimeRangeReq(contractReq()),
+			"/v4/sixun/saleTotalByTimeRange", with()),
+		get("sixun.SaleTrend", sixun.NewSaleTrendReq(contractReq()),
+			"/v4/sixun/saleTrend", with()),
+
+		// gadget
+		get("gadget.Weather", gadget.NewWeather("101010100"),
+			"/tools/weather/101010100", url.Values{}),
+		shared(get("gadget.Weather/options", gadget.NewWeather("101010100", gadget.WithEnableForecast(), gadget.WithEnableAQI(), gadget.WithEnableWarnings(), gadget.WithEnableIndex()),
+			"/tools/weather/101010100", url.Values{"forecast": {"1"}, "aqi": {"1"}, "warnings": {"1"}, "index": {"1"}})),
+	}
+}
+
+func TestContract_Requests(t *testing.T) {
+	for _, c := range contracts() {
+		t.Run(c.name, func(t *testing.T) {
+			u, err := url.ParseRequestURI(c.req.Api())
+			if err != nil {
+				t.Fatalf("parse %q: %v", c.req.Api(), err)
+			}
+			if u.Path != c.path {
+				t.Errorf("path = %s, want %s", u.Path, c.path)
+			}
+			if got := u.Query(); !reflect.DeepEqual(got, c.params) {
+				t.Errorf("params = %v, want %v", got, c.params)
+			}
+			if c.req.Method() != c.method {
+				t.Errorf("method = %s, want %s", c.req.Method(), c.method)
+			}
+			if c.req.ContentType() != c.contentType {
+				t.Errorf("content type = %s, want %s", c.req.ContentType(), c.contentType)
+			}
+			if c.method == http.MethodGet && c.req.Body() != nil {
+				t.Errorf("GET request has body %s", c.req.Body())
+			}
+		})
+	}
+}
+
+// TestContract_UniquePaths 不同请求不应指向同一接口
+func TestContract_UniquePaths(t *testing.T) {
+	seen := make(map[string]string)
+	for _, c := range contracts() {
+		if c.shared {
+			continue
+		}
+		key := c.method + " " + c.path
+		if prev, ok := seen[key]; ok {
+			t.Errorf("%s and %s both use %s", prev, c.name, key)
+		}
+		seen[key] = c.name
+	}
+}
+
+func TestContract_SalesDetailBody(t *testing.T) {
+	req := product.NewSalesDetailReq(contractReq(), product.WithSalesDetailTicketId([]int{1, 2}))
+	var body map[string]any
+	if err := json.Unmarshal(req.Body(), &body); err != nil {
+		t.Fatal(err)
+	}
+	for key, want := range map[string]any{"sid": 3385.0, "start": "2024-05-01", "end": "2024-05-07", "lid": "101,102"} {
+		if body[key] != want {
+			t.Errorf("body[%s] = %v, want %v", key, body[key], want)
+		}
+	}
+	if ids, ok := body["ticketId"].([]any); !ok || len(ids) != 2 {
+		t.Errorf("body[ticketId] = %v", body["ticketId"])
+	}
+}
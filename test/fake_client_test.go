@@ -0,0 +1,41 @@
+package test
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/piaofutong/odas-sdk/odas"
+	"net/http"
+	"sync"
+)
+
+// fakeClient 不发起网络请求，按请求返回预置的 result
+type fakeClient struct {
+	mutex   sync.Mutex
+	handler func(req *http.Request) (any, error)
+	calls   []*http.Request
+}
+
+func (c *fakeClient) Do(request *http.Request, v any) error {
+	c.mutex.Lock()
+	c.calls = append(c.calls, request)
+	c.mutex.Unlock()
+	result, err := c.handler(request)
+	if err != nil {
+		return err
+	}
+	if raw, ok := result.(string); ok {
+		return json.Unmarshal([]byte(raw), v)
+	}
+	b, err := json.Marshal(result)
+	if err != nil {
+		return fmt.Errorf("marshal fake result: %w", err)
+	}
+	return json.Unmarshal(b, v)
+}
+
+func newFakeIAM(handler func(req *http.Request) (any, error)) (*odas.IAM, *fakeClient) {
+	client := &fakeClient{handler: handler}
+	iam := odas.NewIAM(accessId, accessKey)
+	iam.Client = client
+	return iam, client
+}
@@ -0,0 +1,56 @@
+package test
+
+import (
+	"github.com/piaofutong/odas-sdk/odas"
+	"github.com/piaofutong/odas-sdk/odas/hotel"
+	"github.com/piaofutong/odas-sdk/odas/tourist"
+	"testing"
+)
+
+func TestFillGaps_Daily(t *testing.T) {
+	list := []*hotel.RmOrderDateListData{
+		{Date: "2024-11-03", RmOrderDateTotal: hotel.RmOrderDateTotal{BookingCount: 3}},
+		{Date: "2024-11-01", RmOrderDateTotal: hotel.RmOrderDateTotal{BookingCount: 1}},
+	}
+	filled, err := odas.FillGaps(list, "2024-11-01", "2024-11-04", odas.GranularityDay, func(b odas.Bucket) *hotel.RmOrderDateListData {
+		return &hotel.RmOrderDateListData{Date: b.String()}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []struct {
+		date  string
+		count int
+	}{{"2024-11-01", 1}, {"2024-11-02", 0}, {"2024-11-03", 3}, {"2024-11-04", 0}}
+	if len(filled) != len(want) {
+		t.Fatalf("got %d items", len(filled))
+	}
+	for i, w := range want {
+		if filled[i].Date != w.date || filled[i].BookingCount != w.count {
+			t.Errorf("item %d = %s/%d, want %s/%d", i, filled[i].Date, filled[i].BookingCount, w.date, w.count)
+		}
+	}
+}
+
+func TestFillGaps_Hourly(t *testing.T) {
+	list := []*tourist.InoutList{{Time: "10:00", In: 5}}
+	filled, err := odas.FillGaps(list, "2024-11-22", "2024-11-22", odas.GranularityHour, func(b odas.Bucket) *tourist.InoutList {
+		return &tourist.InoutList{Time: b.Time.Format("15:04")}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(filled) != 24 || filled[10].In != 5 || filled[0].Time != "00:00" || filled[23].Time != "23:00" {
+		t.Fatalf("unexpected fill: %d items", len(filled))
+	}
+}
+
+func TestFillGaps_ClockKeysAcrossDays(t *testing.T) {
+	list := []*tourist.InoutList{{Time: "08:00", In: 5}, {Time: "08:00", In: 7}}
+	_, err := odas.FillGaps(list, "2024-11-22", "2024-11-23", odas.GranularityHour, func(b odas.Bucket) *tourist.InoutList {
+		return &tourist.InoutList{Time: b.Time.Format("15:04")}
+	})
+	if err == nil {
+		t.Fatal("clock-only keys over a multi-day range should be rejected")
+	}
+}
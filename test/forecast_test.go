@@ -0,0 +1,114 @@
+package test
+
+import (
+	"bytes"
+	"github.com/piaofutong/odas-sdk/odas"
+	"github.com/piaofutong/odas-sdk/odas/forecast"
+	"github.com/piaofutong/odas-sdk/odas/tourist"
+	"math"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func syntheticHistory(days int) ([]forecast.Observation, func(time.Time) bool) {
+	start := time.Date(2024, 7, 1, 0, 0, 0, 0, odas.Shanghai)
+	holiday := func(d time.Time) bool { return d.Day() == 15 }
+	var obs []forecast.Observation
+	for i := 0; i < days; i++ {
+		d := start.AddDate(0, 0, i)
+		v := 100.0
+		if d.Weekday() == time.Saturday || d.Weekday() == time.Sunday {
+			v = 200
+		}
+		if holiday(d) {
+			v *= 3
+		}
+		obs = append(obs, forecast.Observation{Date: d, Count: v})
+	}
+	return obs, holiday
+}
+
+func TestForecast_Seasonal(t *testing.T) {
+	history, holiday := syntheticHistory(120)
+	points, err := forecast.Forecast(history, 21, forecast.WithHolidays(holiday))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(points) != 21 {
+		t.Fatalf("got %d points", len(points))
+	}
+	for _, p := range points {
+		want := 100.0
+		if p.Date.Weekday() == time.Saturday || p.Date.Weekday() == time.Sunday {
+			want = 200
+		}
+		if p.Holiday {
+			want *= 3
+		}
+		if math.Abs(p.Value-want)/want > 0.05 {
+			t.Errorf("%s: forecast %.1f, want ~%.0f", p.Date.Format("2006-01-02"), p.Value, want)
+		}
+		if p.Lower > p.Value || p.Upper < p.Value {
+			t.Errorf("%s: interval [%.1f, %.1f] excludes %.1f", p.Date.Format("2006-01-02"), p.Lower, p.Upper, p.Value)
+		}
+	}
+}
+
+func TestForecast_Backtest(t *testing.T) {
+	history, holiday := syntheticHistory(120)
+	result, err := forecast.Backtest(history, 14, 3, forecast.WithHolidays(holiday))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Points) != 42 || result.MAPE > 0.05 || result.Coverage < 0.9 {
+		t.Fatalf("unexpected backtest: points=%d mape=%.3f coverage=%.2f", len(result.Points), result.MAPE, result.Coverage)
+	}
+}
+
+func TestTracker_Evaluate(t *testing.T) {
+	store := forecast.NewFileStore(filepath.Join(t.TempDir(), "snapshots.jsonl"))
+	tracker := forecast.NewTracker(store)
+	taken := time.Date(2024, 11, 1, 9, 0, 0, 0, odas.Shanghai)
+	err := tracker.Record(&forecast.Snapshot{
+		Sid:     sid,
+		TakenAt: taken,
+		Start:   "2024-11-02",
+		End:     "2024-11-03",
+		List: &tourist.ForecastPassengerFlowListResponse{
+			Total: &tourist.FlowForecastTotal{TodayFlow: 110, TomorrowFlow: 90, ThirdDayFlow: 100},
+		},
+		Summary: &tourist.ForecastPassengerFlowSummaryResponse{Total: 150},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	actual, err := forecast.ActualsFromPassengerFlow(&tourist.PassengerFlowByDateResponse{List: []*tourist.PassengerFlowByDateList{
+		{Date: 20241101, Count: 100}, {Date: 20241102, Count: 100},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	report, err := tracker.Evaluate(map[int]forecast.Actuals{sid: actual})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 后日与汇总缺少实际值
+	if len(report.Rows) != 2 || report.Skipped != 2 {
+		t.Fatalf("unexpected report: %+v skipped=%d", report.Rows, report.Skipped)
+	}
+	if r := report.Rows[0]; r.Horizon != 0 || math.Abs(r.MAPE-0.1) > 1e-9 || math.Abs(r.Bias-0.1) > 1e-9 {
+		t.Errorf("unexpected today row: %+v", r)
+	}
+	if r := report.Rows[1]; r.Horizon != 1 || math.Abs(r.Bias+0.1) > 1e-9 {
+		t.Errorf("unexpected tomorrow row: %+v", r)
+	}
+	var buf bytes.Buffer
+	if err = report.WriteCSV(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(buf.String(), "sid,kind,horizon,samples,mape,bias\n3385,daily,0,1,0.1000,0.1000\n") {
+		t.Errorf("unexpected csv:\n%s", buf.String())
+	}
+}
@@ -0,0 +1,180 @@
+package test
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/piaofutong/odas-sdk/odas/geo"
+	"github.com/piaofutong/odas-sdk/odas/portrait"
+	"github.com/piaofutong/odas-sdk/odas/tourist"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestGeo_Normalize(t *testing.T) {
+	cases := map[string]string{
+		"浙江省":        "浙江",
+		"浙江":         "浙江",
+		"广西壮族自治区":    "广西",
+		"内蒙古自治区":     "内蒙古",
+		"新疆维吾尔自治区":   "新疆",
+		"香港特别行政区":    "香港",
+		"延边朝鲜族自治州":   "延边",
+		"红河哈尼族彝族自治州": "红河",
+		"海西蒙古族藏族自治州": "海西",
+		"浦东新区":       "浦东",
+		"市辖区":        "",
+		" 杭州市 ":      "杭州",
+	}
+	for in, want := range cases {
+		if got := geo.Normalize(in); got != want {
+			t.Errorf("Normalize(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestGeo_TreeLookup(t *testing.T) {
+	tree := geo.Default()
+	if p, ok := tree.Province("福建"); !ok || p.Name != "福建省" || len(p.Children) != 9 {
+		t.Fatalf("province = %+v", p)
+	}
+	c, err := tree.City("", "大理")
+	if err != nil || c == nil || c.Parent.Name != "云南省" {
+		t.Fatalf("city = %+v, %v", c, err)
+	}
+	if c, _ = tree.City("浙江", "厦门"); c != nil {
+		t.Errorf("厦门 should not resolve under 浙江")
+	}
+	custom := geo.NewTree()
+	custom.Register("甲省", "同名市")
+	custom.Register("乙省", "同名市")
+	if _, err = custom.City("", "同名"); !errors.Is(err, geo.ErrAmbiguous) {
+		t.Errorf("want ambiguous, got %v", err)
+	}
+}
+
+func TestGeo_Rollup(t *testing.T) {
+	r := geo.NewRollup(nil)
+	r.AddLocalList([]*tourist.LocalByTicketResponseListItem{
+		{ProvinceName: "浙江省", CityName: "杭州市", DistrictName: "西湖区", Count: 30},
+		{ProvinceName: "浙江", CityName: "杭州", DistrictName: "上城区", Count: 10},
+		{ProvinceName: "浙江省", CityName: "宁波市", Count: 20},
+		{ProvinceName: "北京市", CityName: "市辖区", DistrictName: "朝阳区", Count: 5},
+	})
+	r.AddCities([]*portrait.CityRankResponse{{City: "厦门市", Total: 25}, {City: "不存在市", Total: 5}})
+	r.AddLocations(&portrait.CountryProvinceLocationRankResponse{List: []*portrait.CountryProvinceLocationRankItem{
+		{Country: "日本", Count: 5},
+	}})
+	root := r.Result()
+	if root.Count != 100 {
+		t.Fatalf("total = %d", root.Count)
+	}
+	china := root.Find("中国")
+	if china == nil || china.Count != 95 || china.Share != 0.95 {
+		t.Fatalf("china = %+v", china)
+	}
+	zj := china.Find("浙江")
+	if zj == nil || zj.Name != "浙江省" || zj.Count != 60 || zj.Children[0].Name != "杭州市" {
+		t.Fatalf("zhejiang = %+v", zj)
+	}
+	hz := zj.Find("杭州")
+	if hz.Count != 40 || hz.Share != 40.0/60 || math.Abs(hz.TotalShare-0.4) > 1e-9 {
+		t.Errorf("hangzhou = %+v", hz)
+	}
+	if n := china.Find("福建", "厦门"); n == nil || n.Count != 25 {
+		t.Errorf("xiamen should roll up to 福建省: %+v", n)
+	}
+	if n := china.Find(geo.Unknown, "不存在"); n == nil || n.Count != 5 {
+		t.Errorf("unresolved city should sit under unknown province: %+v", n)
+	}
+	if n := china.Find("北京", "北京", "朝阳"); n == nil || n.Level != geo.LevelDistrict {
+		t.Errorf("municipality district = %+v", n)
+	}
+	provinces := root.Flatten(geo.LevelProvince)
+	if len(provinces) != 4 || provinces[0].Name != "浙江省" {
+		t.Errorf("provinces = %+v", provinces)
+	}
+}
+
+func TestGeo_Choropleth(t *testing.T) {
+	list := []*portrait.ProvinceRankResponse{
+		{Province: "浙江", Total: 60, Rate: 0.6, CompareTotalRate: 0.1},
+		{Province: "火星", Total: 40, Rate: 0.4},
+	}
+	// 内置数据带外接矩形，无需载入边界即可着色
+	fc, unmatched := geo.DefaultShapes().Choropleth(geo.LevelProvince, geo.ProvinceCompareRegions(list))
+	if len(fc.Features) != 1 || len(unmatched) != 1 || unmatched[0] != "火星" {
+		t.Fatalf("features=%d unmatched=%v", len(fc.Features), unmatched)
+	}
+	f := fc.Features[0]
+	if f.Geometry.Type != "Polygon" || f.Properties["name"] != "浙江省" || f.Properties["count"] != 60 || f.Properties["compare"] != 0.1 {
+		t.Errorf("feature = %+v %+v", f.Geometry.Type, f.Properties)
+	}
+	if string(f.Geometry.Coordinates) != "[[[118,27],[123,27],[123,31.2],[118,31.2],[118,27]]]" {
+		t.Errorf("coordinates = %s", f.Geometry.Coordinates)
+	}
+	b, err := json.Marshal(fc)
+	if err != nil || !strings.Contains(string(b), `"type":"FeatureCollection"`) {
+		t.Errorf("marshal = %s, %v", b, err)
+	}
+	// 载入的边界覆盖内置矩形
+	shapes := geo.NewShapes()
+	err = shapes.LoadGeoJSON(strings.NewReader(`{"type":"FeatureCollection","features":[
+		{"type":"Feature","properties":{"name":"浙江省"},"geometry":{"type":"MultiPolygon","coordinates":[[[[118,27],[123,27],[123,31],[118,27]]]]}}
+	]}`), geo.LevelProvince)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fc, _ := shapes.Choropleth(geo.LevelProvince, geo.ProvinceRegions(list)); len(fc.Features) != 1 || fc.Features[0].Geometry.Type != "MultiPolygon" {
+		t.Errorf("loaded features = %+v", fc.Features)
+	}
+	// 不带对比的构造不输出 compare
+	for _, r := range geo.ProvinceRegions(list) {
+		if r.Compare != nil {
+			t.Errorf("%s compare = %v", r.Name, *r.Compare)
+		}
+	}
+
+	cities, provinces := geo.LocalRegions(&tourist.LocalResponse{
+		Inside:  []*tourist.LocalInsideProvinceList{{City: "杭州", Count: 5}, {City: "宁波市", Count: 3}},
+		Outside: []*tourist.LocalOutsideProvinceList{{Province: "江苏省", Count: 2}},
+	})
+	points, unmatched := geo.DefaultShapes().Points(geo.LevelCity, cities)
+	if len(points.Features) != 2 || len(unmatched) != 0 || string(points.Features[0].Geometry.Coordinates) != "[120.16,30.27]" {
+		t.Errorf("points = %+v, unmatched %v", points.Features[0].Geometry, unmatched)
+	}
+	if len(provinces) != 1 || provinces[0].Name != "江苏省" {
+		t.Errorf("provinces = %+v", provinces)
+	}
+}
+
+func TestGeo_DefaultShapesCoverDivisions(t *testing.T) {
+	shapes := geo.DefaultShapes()
+	for _, p := range geo.Default().Provinces() {
+		for _, d := range append([]*geo.Division{p}, p.Children...) {
+			shape, ok := shapes.Lookup(d.Level, d.Name)
+			if !ok || shape.Geometry == nil {
+				t.Errorf("%s %s has no built-in shape", d.Level, d.Name)
+			}
+		}
+	}
+}
+
+func TestGeo_LoadGeoJSON(t *testing.T) {
+	shapes := geo.NewShapes()
+	err := shapes.LoadGeoJSON(strings.NewReader(`{"type":"FeatureCollection","features":[
+		{"type":"Feature","properties":{"name":"杭州市","center":[120.15,30.28]},"geometry":{"type":"Polygon","coordinates":[[[119,29],[121,29],[121,31],[119,29]]]}},
+		{"type":"Feature","properties":{"name":"宁波市"},"geometry":{"type":"MultiPolygon","coordinates":[[[[121,29],[122,29],[122,30],[121,29]]]]}}
+	]}`), geo.LevelCity)
+	if err != nil {
+		t.Fatal(err)
+	}
+	nb, ok := shapes.Lookup(geo.LevelCity, "宁波")
+	if !ok || nb.Center != [2]float64{121.5, 29.5} {
+		t.Fatalf("ningbo = %+v", nb)
+	}
+	fc, _ := shapes.Choropleth(geo.LevelCity, []geo.Region{{Name: "杭州", Count: 1}})
+	if len(fc.Features) != 1 || fc.Features[0].Geometry.Type != "Polygon" {
+		t.Errorf("features = %+v", fc.Features)
+	}
+}
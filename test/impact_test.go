@@ -0,0 +1,108 @@
+package test
+
+import (
+	"encoding/json"
+	"github.com/piaofutong/odas-sdk/odas"
+	"github.com/piaofutong/odas-sdk/odas/gadget"
+	"github.com/piaofutong/odas-sdk/odas/impact"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestAnalyzeWeather_RainImpact(t *testing.T) {
+	start := time.Date(2024, 6, 3, 0, 0, 0, 0, odas.Shanghai)
+	var weather []*impact.DailyWeather
+	visits := impact.Visits{}
+	for i := 0; i < 28; i++ {
+		d := start.AddDate(0, 0, i)
+		w := &impact.DailyWeather{Date: d, TempMaxC: 22}
+		v := 1000.0
+		// 前两周的周三下雨
+		if i%7 == 2 && i < 14 {
+			w.PrecipMm = 15
+			v = 500
+		}
+		weather = append(weather, w)
+		visits[d.Format("2006-01-02")] = v
+	}
+	r, err := impact.AnalyzeWeather(weather, visits)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Days != 28 || len(r.Precip) != 2 {
+		t.Fatalf("days=%d precip bands=%d", r.Days, len(r.Precip))
+	}
+	dry, rain := r.Precip[0], r.Precip[1]
+	if dry.Label != "无雨" || rain.Label != "中雨" || rain.Days != 2 {
+		t.Fatalf("bands = %+v %+v", dry, rain)
+	}
+	// 周三基线为 750，雨天为基线的 2/3
+	if rain.MeanVisits != 500 || math.Abs(rain.Impact+1.0/3) > 1e-9 {
+		t.Errorf("rain mean=%v impact=%v", rain.MeanVisits, rain.Impact)
+	}
+
+	var resp gadget.WeatherResponse
+	err = json.Unmarshal([]byte(`{
+		"forecast":[{"fxDate":"2024-07-03","tempMax":"22","tempMin":"15","precip":"12.0"},
+			{"fxDate":"2024-07-01","tempMax":"23","tempMin":"16","precip":"0.0"}],
+		"aqi":{"pubTime":"2024-07-01T08:00+08:00","aqi":"40"}
+	}`), &resp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp, err := r.ExpectedFromForecast(&resp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(exp) != 2 || exp[0].Weather.AQI != 40 || exp[1].Weather.AQI != 0 {
+		t.Fatalf("expectations = %+v", exp)
+	}
+	if exp[0].Baseline != 1000 || math.Abs(exp[0].Expected-1000*(1+dry.Impact)) > 1e-9 {
+		t.Errorf("dry day expected %v, baseline %v", exp[0].Expected, exp[0].Baseline)
+	}
+	if math.Abs(exp[1].Expected-500) > 1e-9 {
+		t.Errorf("rainy day expected %v", exp[1].Expected)
+	}
+}
+
+func TestAnalyzeWeather_NoOverlap(t *testing.T) {
+	w := []*impact.DailyWeather{{Date: time.Date(2024, 6, 3, 0, 0, 0, 0, odas.Shanghai)}}
+	if _, err := impact.AnalyzeWeather(w, impact.Visits{"2024-06-04": 1}); err == nil {
+		t.Fatal("expected error")
+	}
+}
+
+func TestAnalyzeWeather_LatestObservationPerDate(t *testing.T) {
+	day := time.Date(2024, 6, 5, 0, 0, 0, 0, odas.Shanghai)
+	early := time.Date(2024, 6, 3, 8, 0, 0, 0, odas.Shanghai)
+	late := time.Date(2024, 6, 5, 8, 0, 0, 0, odas.Shanghai)
+	weather := []*impact.DailyWeather{
+		{Date: day, TempMaxC: 22, ObservedAt: late},
+		{Date: day, TempMaxC: 22, PrecipMm: 15, ObservedAt: early},
+	}
+	r, err := impact.AnalyzeWeather(weather, impact.Visits{"2024-06-05": 800})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Days != 1 || len(r.Precip) != 1 || r.Precip[0].Label != "无雨" {
+		t.Fatalf("days=%d precip=%+v", r.Days, r.Precip)
+	}
+
+	var resp gadget.WeatherResponse
+	err = json.Unmarshal([]byte(`{
+		"now":{"obsTime":"2024-07-01T08:00+08:00"},
+		"forecast":[{"fxDate":"2024-07-01","tempMax":"23","tempMin":"16","precip":"0.0"},
+			{"fxDate":"2024-07-02","tempMax":"","tempMin":"16","precip":"1.0"}]
+	}`), &resp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	daily, err := impact.DailyWeatherFrom(&resp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(daily) != 1 || daily[0].ObservedAt.Hour() != 8 {
+		t.Fatalf("daily = %+v", daily)
+	}
+}
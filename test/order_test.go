@@ -1,8 +1,11 @@
 package test
 
 import (
+	"encoding/json"
 	"github.com/piaofutong/odas-sdk/odas"
 	"github.com/piaofutong/odas-sdk/odas/order"
+	"os"
+	"reflect"
 	"testing"
 )
 
@@ -123,3 +126,57 @@ func TestService_PrebookingSummary(t *testing.T) {
 		t.Fatal(err)
 	}
 }
+
+func TestSummaryResponse_Golden(t *testing.T) {
+	golden, err := os.ReadFile("testdata/order_summary.golden.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var r order.SummaryResponse
+	if err = json.Unmarshal(golden, &r); err != nil {
+		t.Fatal(err)
+	}
+	groups := []order.SummaryMetric{r.Order, r.Verify, r.Refund, r.Finish, r.Cancel, r.AfterSale}
+	for _, g := range groups {
+		for _, m := range []odas.Metric{g.Ticket, g.Amount} {
+			if m.Value == 0 || m.Mom == nil || m.Yoy == nil {
+				t.Fatalf("metric not fully decoded: %+v", m)
+			}
+		}
+	}
+
+	encoded, err := json.Marshal(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var want, got map[string]any
+	_ = json.Unmarshal(golden, &want)
+	_ = json.Unmarshal(encoded, &got)
+	if !reflect.DeepEqual(want, got) {
+		t.Fatalf("round trip mismatch:\nwant %v\n got %v", want, got)
+	}
+}
+
+func TestSummaryResponse_LegacyYoyVerifyTicket(t *testing.T) {
+	var r order.SummaryResponse
+	if err := json.Unmarshal([]byte(`{"verifyTicket":3,"yoyVerifyT":0.5}`), &r); err != nil {
+		t.Fatal(err)
+	}
+	if r.Verify.Ticket.Value != 3 || r.Verify.Ticket.Yoy == nil || *r.Verify.Ticket.Yoy != 0.5 {
+		t.Fatalf("unexpected verify ticket: %+v", r.Verify.Ticket)
+	}
+}
+
+func TestSummaryResponse_IgnoresUnknownFields(t *testing.T) {
+	var r order.SummaryResponse
+	data := `{"orderTicket":2.6,"orderAmount":100,"momOrderAmount":null,"updatedAt":"2024-05-01 10:00:00","extra":{"a":1}}`
+	if err := json.Unmarshal([]byte(data), &r); err != nil {
+		t.Fatal(err)
+	}
+	if r.Order.Ticket.Value != 3 || r.Order.Amount.Value != 100 || r.Order.Amount.Mom != nil {
+		t.Fatalf("unexpected order: %+v", r.Order)
+	}
+	if err := json.Unmarshal([]byte(`{"orderTicket":"x"}`), &r); err == nil {
+		t.Fatal("expected error for non-numeric known field")
+	}
+}
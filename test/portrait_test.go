@@ -1,8 +1,15 @@
 package test
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"github.com/piaofutong/odas-sdk/odas"
+	"github.com/piaofutong/odas-sdk/odas/order"
 	"github.com/piaofutong/odas-sdk/odas/portrait"
+	"math"
+	"net/http"
+	"strings"
 	"testing"
 )
 
@@ -161,3 +168,244 @@ func TestService_SexAgeSummaryByVerify(t *testing.T) {
 		t.Fatal(err)
 	}
 }
+
+func TestProfile_PicksBasisVariants(t *testing.T) {
+	responses := map[string]string{
+		"ageSummaryByVerify":                  `{"total":{"total":3,"male":1,"female":2},"list":[{"ageGroup":"18-25","male":1,"female":2}]}`,
+		"provinceByVerify":                    `[{"province":"福建省","total":3}]`,
+		"cityByVerify":                        `[{"city":"厦门市","total":3}]`,
+		"fellow":                              `{"total":3,"list":[{"name":"2人","count":3}]}`,
+		"paymentMethod":                       `[{"name":"微信","count":3}]`,
+		"verifiedCountryProvinceLocationRank": `{"list":[{"country":"中国","provinceName":"福建省","count":3}]}`,
+	}
+	iam, client := newFakeIAM(func(req *http.Request) (any, error) {
+		name := strings.TrimPrefix(req.URL.Path, "/v4/portrait/")
+		if r, ok := responses[name]; ok {
+			return r, nil
+		}
+		return nil, errors.New("unexpected " + name)
+	})
+	req := &portrait.ProfileReq{
+		Req:      odas.Req{DateRangeReq: odas.DateRangeReq{Sid: sid, Start: start, End: end}},
+		Province: "福建省",
+		Limit:    5,
+	}
+	p, err := portrait.Profile(context.Background(), iam, req, portrait.BasisVerify, odas.WithToken(token))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(p.Errors) != 0 {
+		t.Fatalf("errors: %v", p.Errors)
+	}
+	if p.SexAge.Total.Female != 2 || p.Provinces[0].Total != 3 || p.Cities[0].City != "厦门市" ||
+		p.Fellow.Total != 3 || p.Payment.List[0].ChannelName != "微信" || p.Payment.Total != 3 || p.Locations.List[0].Count != 3 {
+		t.Fatalf("unexpected profile: %+v", p)
+	}
+	if len(client.calls) != 6 {
+		t.Fatalf("calls = %d", len(client.calls))
+	}
+	for _, call := range client.calls {
+		if call.URL.Query().Get("province") == "" && !strings.HasSuffix(call.URL.Path, "/provinceByVerify") {
+			t.Errorf("%s missing province", call.URL)
+		}
+	}
+}
+
+func TestProfile_PartialFailure(t *testing.T) {
+	iam, _ := newFakeIAM(func(req *http.Request) (any, error) {
+		if strings.HasSuffix(req.URL.Path, "/fellowByTicket") {
+			return `{"total":2,"list":[{"name":"独自","count":2,"rate":1}]}`, nil
+		}
+		return nil, errors.New("boom")
+	})
+	req := &portrait.ProfileReq{Req: odas.Req{DateRangeReq: odas.DateRangeReq{Sid: sid, Start: start, End: end}}}
+	p, err := portrait.Profile(context.Background(), iam, req, portrait.BasisTicket, odas.WithToken(token))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Fellow.List[0].Name != "独自" || len(p.Errors) != 5 || p.SexAge != nil {
+		t.Fatalf("unexpected profile: %+v", p)
+	}
+	if _, ok := p.Errors[portrait.SectionPayment]; !ok {
+		t.Errorf("payment error missing: %v", p.Errors)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err = portrait.Profile(ctx, iam, req, portrait.BasisBooking, odas.WithToken(token)); !errors.Is(err, context.Canceled) {
+		t.Errorf("want context.Canceled, got %v", err)
+	}
+}
+
+func TestBasis_Endpoints(t *testing.T) {
+	req := &odas.Req{DateRangeReq: odas.DateRangeReq{Sid: sid, Start: start, End: end}}
+	compare := &odas.DateRangeCompareReq{}
+	cases := []struct {
+		req  odas.IRequest
+		path string
+	}{
+		{portrait.NewSexAgeSummaryReq(req), "/v4/portrait/ageSummary"},
+		{portrait.NewSexAgeSummaryReq(req, portrait.WithSexAgeBasis(portrait.BasisTicket)), "/v4/portrait/ageSummaryByTicket"},
+		{portrait.NewSexAgeSummaryByVerifyReq(req), "/v4/portrait/ageSummaryByVerify"},
+		{portrait.NewProvinceReq(req, compare), "/v4/portrait/province"},
+		{portrait.NewProvinceByVerifyReq(req, compare), "/v4/portrait/provinceByVerify"},
+		{portrait.NewCityReq(req, compare, portrait.WithCityBasis(portrait.BasisVerify)), "/v4/portrait/cityByVerify"},
+		{portrait.NewFellowByTicketReq(req), "/v4/portrait/fellowByTicket"},
+		{portrait.NewFellowReq(req, portrait.WithFellowBasis(portrait.BasisBooking)), "/v4/portrait/fellow"},
+		{portrait.NewPaymentMethodByTicketReq(req, portrait.WithPaymentMethodByTicketLimit(3)), "/v4/portrait/paymentMethodByTicket"},
+	}
+	for _, c := range cases {
+		path, _, _ := strings.Cut(c.req.Api(), "?")
+		if path != c.path {
+			t.Errorf("got %s, want %s", path, c.path)
+		}
+	}
+	if api := portrait.NewPaymentMethodByTicketReq(req, portrait.WithPaymentMethodByTicketLimit(3)).Api(); !strings.Contains(api, "limit=3") {
+		t.Errorf("deprecated option dropped: %s", api)
+	}
+	if r := portrait.NewSexAgeSummaryByTicketReq(req); r.Sid != 0 || r.Options.Basis != portrait.BasisTicket {
+		t.Errorf("by ticket req = %+v", r)
+	}
+}
+
+func TestBasis_RejectsUnsupported(t *testing.T) {
+	iam, client := newFakeIAM(func(*http.Request) (any, error) { return `{}`, nil })
+	req := &odas.Req{DateRangeReq: odas.DateRangeReq{Sid: sid, Start: start, End: end}}
+	compare := &odas.DateRangeCompareReq{}
+	for _, r := range []odas.IRequest{
+		portrait.NewProvinceReq(req, compare, portrait.WithProvinceBasis(portrait.BasisTicket)),
+		portrait.NewCityReq(req, compare, portrait.WithCityBasis(portrait.BasisTicket)),
+		portrait.NewFellowReq(req, portrait.WithFellowBasis(portrait.BasisVerify)),
+		portrait.NewPaymentMethodReq(req, portrait.WithPaymentMethodBasis(portrait.BasisVerify)),
+		portrait.NewSexAgeSummaryReq(req, portrait.WithSexAgeBasis(portrait.Basis(9))),
+	} {
+		var v any
+		if err := iam.Do(r, &v, odas.WithToken(token)); err == nil {
+			t.Errorf("expected error for %s", r.Api())
+		}
+		// 直接调用 Api 也不能退回预订口径的路径
+		if api := r.Api(); !strings.Contains(api, "/unsupported/") {
+			t.Errorf("unsupported basis api = %s", api)
+		}
+	}
+	if len(client.calls) != 0 {
+		t.Errorf("unsupported basis sent %d requests", len(client.calls))
+	}
+}
+
+func TestPaymentMethodSummary_BothShapes(t *testing.T) {
+	var booking, ticket portrait.PaymentMethodSummary
+	if err := json.Unmarshal([]byte(`[{"name":"微信","count":6,"rate":0.6},{"name":"支付宝","count":4,"rate":0.4}]`), &booking); err != nil {
+		t.Fatal(err)
+	}
+	if booking.Total != 10 || booking.List[1].ChannelName != "支付宝" || booking.List[0].TicketCountRate != 0.6 {
+		t.Errorf("booking = %+v", booking)
+	}
+	err := json.Unmarshal([]byte(`{"total":5,"list":[{"id":1,"name":"微信","ticket_count":5,"amount":500,"amount_rate":1,"ticket_count_rate":1}]}`), &ticket)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ticket.Total != 5 || ticket.List[0].Amount != 500 || ticket.List[0].ChannelId != 1 {
+		t.Errorf("ticket = %+v", ticket)
+	}
+}
+
+func TestParseAgeBand(t *testing.T) {
+	cases := map[string]string{
+		"18-25":  "18-25",
+		"18~25岁": "18-25",
+		"61+":    "61+",
+		"60岁以上":  "60+",
+		"18岁以下":  "0-17",
+		"<18":    "0-17",
+		"17岁及以下": "0-17",
+		"未知":     "未知",
+		"":       "未知",
+	}
+	for label, want := range cases {
+		band, err := portrait.ParseAgeBand(label)
+		if err != nil || band.String() != want {
+			t.Errorf("ParseAgeBand(%q) = %v, %v; want %s", label, band, err, want)
+		}
+	}
+	if _, err := portrait.ParseAgeBand("青年"); err == nil {
+		t.Error("expected error for free text")
+	}
+}
+
+func TestAgePyramid_AboveAfterClosedBand(t *testing.T) {
+	p, err := portrait.AgePyramidFromSummary(&portrait.AgeSummaryResponse{List: []*portrait.AgeSummaryList{
+		{AgeGroup: "18-60", Male: 10, Female: 10},
+		{AgeGroup: "60以上", Male: 5, Female: 5},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(p.Rows) != 2 || p.Rows[1].Band.String() != "61+" {
+		t.Errorf("rows = %v %v", p.Rows[0].Band, p.Rows[1].Band)
+	}
+	// "及以上" 明确含 N，仍按重叠处理
+	if _, err = portrait.AgePyramidFromSummary(&portrait.AgeSummaryResponse{List: []*portrait.AgeSummaryList{
+		{AgeGroup: "18-60"}, {AgeGroup: "60及以上"},
+	}}); err == nil {
+		t.Error("expected overlap error for 60及以上")
+	}
+}
+
+func TestAgePyramid(t *testing.T) {
+	p, err := portrait.AgePyramidFromSummary(&portrait.AgeSummaryResponse{List: []*portrait.AgeSummaryList{
+		{AgeGroup: "18岁以下", Male: 10, Female: 10},
+		{AgeGroup: "18-29", Male: 20, Female: 20},
+		{AgeGroup: "30-39", Male: 10, Female: 10},
+		{AgeGroup: "40岁以上", Male: 10, Female: 10},
+		{AgeGroup: "未知", Male: 5, Female: 15},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Known != 100 || p.Total != 120 || p.Unknown.Total != 20 || math.Abs(p.UnknownPercent-20.0/120) > 1e-9 {
+		t.Fatalf("totals: known=%v total=%v unknown=%v", p.Known, p.Total, p.Unknown.Total)
+	}
+	if p.Rows[1].Percent != 0.4 {
+		t.Errorf("18-29 percent = %v", p.Rows[1].Percent)
+	}
+	// 中位数第 50 人落在 18-29 段的第 30 人处
+	if m, ok := p.MedianAge(); !ok || math.Abs(m-(18+30.0/40*12)) > 1e-9 {
+		t.Errorf("median = %v", m)
+	}
+
+	d, err := p.Decades(40)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(d.Rows) != 5 || d.Rows[4].Band.String() != "40+" || d.Known != 100 || d.Unknown.Total != 20 {
+		t.Fatalf("decades = %+v", d.Rows)
+	}
+	// 0-17 拆到 0-9 与 10-17，18-29 拆到 10-19(2 岁)与 20-29
+	if math.Abs(d.Rows[1].Total-(20*8.0/18+40*2.0/12)) > 1e-9 {
+		t.Errorf("10-19 total = %v", d.Rows[1].Total)
+	}
+
+	if _, err = portrait.NewAgePyramid([]*portrait.AgeRow{
+		{Band: portrait.AgeBand{Min: 0, Max: 20}},
+		{Band: portrait.AgeBand{Min: 10, Max: 30}},
+	}); err == nil {
+		t.Error("expected overlap error")
+	}
+}
+
+func TestAgePyramidFromPreBooking(t *testing.T) {
+	p, err := portrait.AgePyramidFromPreBooking(&order.PreBookingAgeGenderDistResponse{List: []*order.AgeGenderCountListItem{
+		{MaleAge18to27: 3, FemaleAge18to27: 1, MaleAge61plus: 2},
+		{MaleAge18to27: 1, FemaleAge28to40: 4},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(p.Rows) != 6 || p.Rows[2].Male != 4 || p.Rows[2].Band.String() != "18-27" || p.Female != 5 || p.Known != 11 {
+		t.Fatalf("pyramid = %+v", p.Rows[2])
+	}
+	if m, ok := p.MedianAgeFemale(); !ok || m < 28 || m > 41 {
+		t.Errorf("female median = %v", m)
+	}
+}
@@ -1,8 +1,14 @@
 package test
 
 import (
+	"errors"
+	"fmt"
 	"github.com/piaofutong/odas-sdk/odas"
+	"github.com/piaofutong/odas-sdk/odas/order"
 	"github.com/piaofutong/odas-sdk/odas/product"
+	"github.com/piaofutong/odas-sdk/odas/report"
+	"net/http"
+	"strconv"
 	"testing"
 )
 
@@ -62,3 +68,113 @@ func TestService_TicketList(t *testing.T) {
 		t.Fatal(err)
 	}
 }
+
+// fakeTicketPages 按 lid 返回分页票列表，lid 为空时返回全部
+func fakeTicketPages(tickets map[string][]int) func(req *http.Request) (any, error) {
+	return func(req *http.Request) (any, error) {
+		q := req.URL.Query()
+		ids := tickets[q.Get("lid")]
+		page, _ := strconv.Atoi(q.Get("page"))
+		size, _ := strconv.Atoi(q.Get("pageSize"))
+		if page < 1 || size < 1 {
+			return nil, errors.New("missing paging " + q.Encode())
+		}
+		r := &product.TicketListResponse{Pagination: odas.Pagination{Page: page, PageSize: size, Total: len(ids), Pages: (len(ids) + size - 1) / size}}
+		for i := (page - 1) * size; i < len(ids) && i < page*size; i++ {
+			r.List = append(r.List, &product.TicketList{TicketId: ids[i], TicketName: fmt.Sprintf("票%d", ids[i]), Count: 1})
+		}
+		return r, nil
+	}
+}
+
+func TestCatalog_Load(t *testing.T) {
+	iam, client := newFakeIAM(fakeTicketPages(map[string][]int{
+		"":   {1, 2, 3, 4, 5},
+		"10": {1, 2},
+		"20": {6},
+	}))
+	req := &odas.Req{DateRangeReq: odas.DateRangeReq{Sid: sid, Start: start, End: end}}
+	catalog := product.NewCatalog()
+	catalog.PageSize = 2
+	added, err := catalog.Load(iam, req, odas.WithToken(token))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if added != 5 || catalog.Len() != 5 || len(client.calls) != 3 || catalog.Name(5) != "票5" {
+		t.Fatalf("added = %d, len = %d, calls = %d", added, catalog.Len(), len(client.calls))
+	}
+
+	added, err = catalog.LoadLids(iam, req, []int{10, 20}, odas.WithToken(token))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if added != 1 || len(catalog.TicketsByLid(10)) != 2 || catalog.TicketsByLid(20)[0].TicketId != 6 {
+		t.Fatalf("added = %d, lid 10 = %v", added, catalog.TicketsByLid(10))
+	}
+	if missing := catalog.Missing(1, 7, 7, 8); len(missing) != 2 || missing[0] != 7 {
+		t.Errorf("missing = %v", missing)
+	}
+
+	details := catalog.SalesDetails([]*product.SalesDetailResponse{{TicketId: 2}, {TicketId: 9}})
+	if details[0].TicketName() != "票2" || details[1].Ticket != nil {
+		t.Errorf("sales details = %+v", details)
+	}
+	tickets := catalog.ReportTickets([]*report.TicketListData{{TicketId: 3}, {TicketId: 4, TicketName: "原名"}})
+	if tickets[0].Name() != "票3" || tickets[1].Name() != "原名" {
+		t.Errorf("report tickets = %s %s", tickets[0].Name(), tickets[1].Name())
+	}
+	catalog.RegisterLand(10, "西湖")
+	hot := catalog.Hot([]*order.HotResponse{{Lid: 10, TicketCount: 5}})
+	if hot[0].LandName != "西湖" || len(hot[0].Tickets) != 2 || hot[0].TicketCount != 5 {
+		t.Errorf("hot = %+v", hot[0])
+	}
+}
+
+func TestCatalog_ZeroValue(t *testing.T) {
+	catalog := &product.Catalog{PageSize: 50}
+	if !catalog.Add(&product.Ticket{TicketId: 1, TicketName: "票1"}) || catalog.Add(&product.Ticket{TicketId: 1, Lid: 10}) {
+		t.Fatal("expected first add to insert and second to merge")
+	}
+	catalog.RegisterLand(10, "西湖")
+	ticket, _ := catalog.Ticket(1)
+	ticket.TicketName = "改名"
+	catalog.Tickets()[0].Lid = 20
+	if catalog.Name(1) != "票1" || len(catalog.TicketsByLid(10)) != 1 || catalog.LandName(10) != "西湖" {
+		t.Errorf("catalog modified through returned ticket: %+v", catalog.Tickets()[0])
+	}
+}
+
+func TestWalkTicketList_TrustsPages(t *testing.T) {
+	// 服务端每页最多返回 2 条，请求的 pageSize 更大时仍按 pages 翻完
+	pages := fakeTicketPages(map[string][]int{"": {1, 2, 3, 4, 5}})
+	iam, client := newFakeIAM(func(req *http.Request) (any, error) {
+		q := req.URL.Query()
+		q.Set("pageSize", "2")
+		req.URL.RawQuery = q.Encode()
+		return pages(req)
+	})
+	var ids []int
+	err := product.WalkTicketList(iam, &odas.Req{}, 5, func(page *product.TicketListResponse) error {
+		for _, item := range page.List {
+			ids = append(ids, item.TicketId)
+		}
+		return nil
+	}, odas.WithToken(token))
+	if err != nil || len(ids) != 5 || len(client.calls) != 3 {
+		t.Fatalf("err = %v, ids = %v, calls = %d", err, ids, len(client.calls))
+	}
+}
+
+func TestWalkTicketList_StopsOnError(t *testing.T) {
+	iam, client := newFakeIAM(fakeTicketPages(map[string][]int{"": {1, 2, 3, 4, 5}}))
+	stop := errors.New("stop")
+	err := product.WalkTicketList(iam, &odas.Req{}, 2, func(page *product.TicketListResponse) error {
+		if page.Pagination.Page == 2 {
+			return stop
+		}
+		return nil
+	}, odas.WithToken(token))
+	if !errors.Is(err, stop) || len(client.calls) != 2 {
+		t.Fatalf("err = %v, calls = %d", err, len(client.calls))
+	}
+}
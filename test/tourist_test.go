@@ -1,10 +1,17 @@
 package test
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"github.com/piaofutong/odas-sdk/odas"
 	"github.com/piaofutong/odas-sdk/odas/tourist"
+	"net/http"
 	"strconv"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 )
 
 func TestService_FlowByDevice(t *testing.T) {
@@ -182,3 +189,231 @@ func TestService_TouristLocalByVerify(t *testing.T) {
 		t.Fatal(err)
 	}
 }
+
+func TestWatch_EmitsDeltas(t *testing.T) {
+	snapshots := []any{
+		`{"total":{"in":10,"out":2,"hold":8}}`,
+		`{"total":{"in":10,"out":2,"hold":8}}`,
+		errors.New("temporary failure"),
+		`{"total":{"in":15,"out":5,"hold":10}}`,
+		// 入园计数回落即视为整份快照清零，出园增量也取当前累计值
+		`{"total":{"in":4,"out":7,"hold":8}}`,
+	}
+	var n int
+	iam, _ := newFakeIAM(func(req *http.Request) (any, error) {
+		if n >= len(snapshots) {
+			return snapshots[len(snapshots)-1], nil
+		}
+		s := snapshots[n]
+		n++
+		if err, ok := s.(error); ok {
+			return nil, err
+		}
+		return s, nil
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	events := tourist.Watch(ctx, iam, "42,43", time.Millisecond, odas.WithToken(token))
+
+	first := <-events
+	if first.Err != nil || first.In != 10 || first.Hold != 8 {
+		t.Fatalf("unexpected first event: %+v", first)
+	}
+	if e := <-events; e.Err == nil {
+		t.Fatalf("expected error event, got %+v", e)
+	}
+	third := <-events
+	if third.Err != nil || third.In != 5 || third.Out != 3 || third.Hold != 10 {
+		t.Fatalf("unexpected delta event: %+v", third)
+	}
+	if reset := <-events; reset.Err != nil || reset.In != 4 || reset.Out != 7 {
+		t.Fatalf("unexpected reset event: %+v", reset)
+	}
+	cancel()
+	for range events {
+	}
+}
+
+func TestAlertEvaluator_Hysteresis(t *testing.T) {
+	group := &tourist.GroupListResponse{
+		Id:         42,
+		Capacity:   300,
+		UpperLimit: 250,
+		Config: []*tourist.InoutGroupConfig{
+			{Label: "拥挤", Min: 200, Max: 300, Color: "red"},
+			{Label: "舒适", Min: 0, Max: 99, Color: "green"},
+			{Label: "较多", Min: 100, Max: 199, Color: "yellow"},
+		},
+	}
+	var changes []string
+	var limits []bool
+	e := tourist.NewAlertEvaluator([]*tourist.GroupListResponse{group},
+		tourist.WithAlertHysteresis(5),
+		tourist.WithOnBandChange(func(c *tourist.BandChange) { changes = append(changes, c.To.Label) }),
+		tourist.WithOnUpperLimit(func(ev *tourist.UpperLimitEvent) { limits = append(limits, ev.Exceeded) }),
+	)
+	for _, hold := range []int{50, 102, 106, 98, 94, 210, 251, 248, 240} {
+		e.Evaluate(42, hold)
+	}
+	wantChanges := []string{"舒适", "较多", "舒适", "拥挤"}
+	if strings.Join(changes, ",") != strings.Join(wantChanges, ",") {
+		t.Errorf("band changes = %v, want %v", changes, wantChanges)
+	}
+	if len(limits) != 2 || !limits[0] || limits[1] {
+		t.Errorf("upper limit events = %v", limits)
+	}
+	if s := e.State(42); s.Utilization != 0.8 {
+		t.Errorf("utilization = %v", s.Utilization)
+	}
+	if e.Evaluate(7, 10) != nil {
+		t.Error("unknown group should not be evaluated")
+	}
+}
+
+func TestAlertEvaluator_FlowUsesOwnGroup(t *testing.T) {
+	groups := []*tourist.GroupListResponse{
+		{Id: 1, Config: []*tourist.InoutGroupConfig{{Label: "舒适", Min: 0, Max: 99}}},
+		{Id: 2, Config: []*tourist.InoutGroupConfig{{Label: "舒适", Min: 10, Max: 99}}},
+	}
+	var changes []*tourist.BandChange
+	e := tourist.NewAlertEvaluator(groups, tourist.WithOnBandChange(func(c *tourist.BandChange) { changes = append(changes, c) }))
+	flow := &tourist.AggregateFlow{
+		Total: tourist.InoutTotal{Hold: 85},
+		Sites: []*tourist.SiteFlow{
+			{Key: "1", Total: tourist.InoutTotal{Hold: 80}},
+			{Key: "2", Total: tourist.InoutTotal{Hold: 5}},
+		},
+	}
+	if s := e.EvaluateAggregate(1, flow); s == nil || s.Hold != 80 {
+		t.Fatalf("group 1 state = %+v", s)
+	}
+	// 统计组 2 首次评估不在任何档位，不触发档位变化
+	if s := e.EvaluateAggregate(2, flow); s == nil || s.Hold != 5 || s.Band != nil {
+		t.Fatalf("group 2 state = %+v", s)
+	}
+	if len(changes) != 1 || changes[0].GroupId != 1 || changes[0].From != nil || changes[0].To == nil {
+		t.Errorf("band changes = %+v", changes)
+	}
+	if e.EvaluateAggregate(3, flow) != nil {
+		t.Error("group missing from flow should not be evaluated")
+	}
+}
+
+func TestAlertEvaluator_EvaluateFlow(t *testing.T) {
+	groups := []*tourist.GroupListResponse{{Id: 1, Config: []*tourist.InoutGroupConfig{{Label: "舒适", Min: 0, Max: 99}}}}
+	e := tourist.NewAlertEvaluator(groups)
+	if s := e.EvaluateFlow(1, &tourist.FlowByGIdsResponse{Total: tourist.InoutTotal{Hold: 42}}); s == nil || s.Hold != 42 || s.Band == nil {
+		t.Fatalf("state = %+v", s)
+	}
+}
+
+func TestAlertEvaluator_OrderedDispatch(t *testing.T) {
+	groups := []*tourist.GroupListResponse{{Id: 1, Config: []*tourist.InoutGroupConfig{
+		{Label: "舒适", Min: 0, Max: 49},
+		{Label: "拥挤", Min: 50, Max: 100},
+	}}}
+	var changes []*tourist.BandChange
+	e := tourist.NewAlertEvaluator(groups, tourist.WithOnBandChange(func(c *tourist.BandChange) {
+		changes = append(changes, c)
+	}))
+	var wg sync.WaitGroup
+	for i := 0; i < 200; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			e.Evaluate(1, i%2*60)
+		}(i)
+	}
+	wg.Wait()
+	// 每次变化的起点应为上一次变化的终点
+	for i := 1; i < len(changes); i++ {
+		if changes[i].From != changes[i-1].To {
+			t.Fatalf("change %d out of order: from %v after to %v", i, changes[i].From, changes[i-1].To)
+		}
+	}
+	if last := changes[len(changes)-1].To; last != e.State(1).Band {
+		t.Errorf("last change %v does not match state %v", last, e.State(1).Band)
+	}
+}
+
+func TestAnalyzeOccupancy(t *testing.T) {
+	r := tourist.InoutSummaryResponse{List: []*tourist.InoutList{
+		{Time: "10:00", In: 0, Out: 50},
+		{Time: "09:00", In: 100, Out: 0},
+		{Time: "11:00", In: 0, Out: 50},
+	}}
+	day := time.Date(2024, 11, 22, 0, 0, 0, 0, odas.Shanghai)
+	report, err := tourist.AnalyzeOccupancy(r.List, day, tourist.WithOccupancyCapacity(200))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Peak != 100 || report.PeakTime.Hour() != 9 || report.PeakUtilization != 0.5 {
+		t.Errorf("unexpected peak: %d at %s (%v)", report.Peak, report.PeakTime, report.PeakUtilization)
+	}
+	if report.AverageDwell != 90*time.Minute {
+		t.Errorf("average dwell = %s", report.AverageDwell)
+	}
+	if last := report.Points[len(report.Points)-1]; last.Occupancy != 0 {
+		t.Errorf("final occupancy = %d", last.Occupancy)
+	}
+}
+
+func TestDeviceMonitor(t *testing.T) {
+	groups := []*tourist.GroupListResponse{{Id: 1, Gates: []string{"g1", "g2", "g3", "g4"}}}
+	m := tourist.NewDeviceMonitor(groups)
+	at := time.Date(2024, 11, 22, 10, 0, 0, 0, odas.Shanghai)
+	normal := tourist.FlowByDeviceResponse{
+		"g1": {In: 50, Out: 40}, "g2": {In: 45, Out: 50}, "g3": {In: 40, Out: 45}, "g4": {In: 50, Out: 45},
+	}
+	for i := 0; i < 3; i++ {
+		if r := m.Check(at.Add(time.Duration(i)*time.Hour), normal); r.Flagged != 0 {
+			t.Fatalf("normal traffic flagged: %+v", r.Devices)
+		}
+	}
+	report := m.Check(at.Add(3*time.Hour), tourist.FlowByDeviceResponse{
+		"g1": {In: 400, Out: 380}, "g2": {In: 45, Out: 50}, "g3": {In: 90, Out: 5},
+	})
+	issues := map[string][]tourist.DeviceIssue{}
+	for _, d := range report.Devices {
+		issues[d.Device] = d.Issues
+	}
+	if fmt.Sprint(issues["g1"]) != "[spike peer_outlier]" {
+		t.Errorf("g1 issues = %v", issues["g1"])
+	}
+	if fmt.Sprint(issues["g3"]) != "[imbalance]" {
+		t.Errorf("g3 issues = %v", issues["g3"])
+	}
+	if fmt.Sprint(issues["g4"]) != "[silent]" {
+		t.Errorf("g4 issues = %v", issues["g4"])
+	}
+	if report.Flagged != 3 {
+		t.Errorf("flagged = %d", report.Flagged)
+	}
+}
+
+func TestFlowBySids_PartialFailure(t *testing.T) {
+	iam, _ := newFakeIAM(func(req *http.Request) (any, error) {
+		switch req.URL.Query().Get("sid") {
+		case "1":
+			return `{"total":{"in":10,"out":4,"hold":6},"list":[{"time":"9:00","in":4,"out":1},{"time":"10:00","in":6,"out":3}]}`, nil
+		case "2":
+			// 时间键格式与景区 1 不同，按解析后的时间对齐
+			return `{"total":{"in":5,"out":0,"hold":5},"list":[{"time":"10:00~11:00","in":5,"out":0},{"time":"09:00","in":0,"out":0}]}`, nil
+		}
+		return nil, errors.New("boom")
+	})
+	r, err := tourist.FlowBySids(iam, []string{"1", "2", "3"}, odas.WithToken(token))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Failed != 1 || r.Sites[2].Err == nil || r.Total.Hold != 11 || r.Total.In != 15 {
+		t.Fatalf("unexpected aggregate: %+v", r)
+	}
+	if len(r.List) != 2 || r.List[0].Time != "9:00" || r.List[1].Time != "10:00" || r.List[1].In != 11 {
+		t.Fatalf("unexpected merged list: %+v %+v", r.List[0], r.List[1])
+	}
+
+	if _, err = tourist.FlowBySids(iam, []string{"3", "4"}, odas.WithToken(token)); err == nil {
+		t.Fatal("expected error when every site fails")
+	}
+}
@@ -0,0 +1,185 @@
+package test
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"github.com/piaofutong/odas-sdk/odas/division"
+	"github.com/piaofutong/odas-sdk/odas/gadget"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWeather_CombinedOptions(t *testing.T) {
+	req := gadget.NewWeather("101010100", gadget.WithEnableForecast(), gadget.WithEnableAQI(), gadget.WithEnableWarnings(), gadget.WithEnableIndex())
+	_, query, _ := strings.Cut(req.Api(), "?")
+	params, err := url.ParseQuery(query)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []string{"forecast", "aqi", "warnings", "index"} {
+		if params.Get(key) != "1" {
+			t.Errorf("%s not sent: %s", key, req.Api())
+		}
+	}
+}
+
+func TestWeather_TypedValues(t *testing.T) {
+	var r gadget.WeatherResponse
+	err := json.Unmarshal([]byte(`{
+		"now":{"obsTime":"2024-11-22T21:40+08:00","temp":"12","humidity":"65%","precip":"0.3","windSpeed":"14km/h"},
+		"forecast":[{"fxDate":"2024-11-23","sunrise":"06:41","sunset":"","tempMax":"18℃","tempMin":"9","precip":"1.2","uvIndex":"3"}],
+		"aqi":{"aqi":"56","pm2p5":"39"},
+		"warnings":[{"title":"大风蓝色预警","severityColor":"Blue","startTime":"2024-11-22T20:00+08:00","endTime":"2024-11-23T20:00+08:00","status":"active"}]
+	}`), &r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v, _ := r.Now.HumidityPct(); v != 65 {
+		t.Errorf("humidity = %v", v)
+	}
+	if v, _ := r.Now.WindSpeedKmh(); v != 14 {
+		t.Errorf("wind speed = %v", v)
+	}
+	obs, err := r.Now.ObservedAt()
+	if err != nil || obs.Hour() != 21 {
+		t.Errorf("obs time = %v, %v", obs, err)
+	}
+	f := r.Forecast[0]
+	if v, _ := f.TempMaxC(); v != 18 {
+		t.Errorf("temp max = %v", v)
+	}
+	sunrise, err := f.SunriseAt()
+	if err != nil || sunrise.Format("2006-01-02 15:04") != "2024-11-23 06:41" {
+		t.Errorf("sunrise = %v, %v", sunrise, err)
+	}
+	if _, err = f.SunsetAt(); !errors.Is(err, gadget.ErrNoValue) {
+		t.Errorf("empty sunset should be ErrNoValue, got %v", err)
+	}
+	if v, _ := r.AQI.AQIValue(); v != 56 {
+		t.Errorf("aqi = %v", v)
+	}
+	if len(r.Warnings) != 1 || !r.Warnings[0].Active(obs) || r.Warnings[0].Active(obs.Add(48*time.Hour)) {
+		t.Errorf("unexpected warnings: %+v", r.Warnings)
+	}
+}
+
+func TestWeather_UnitConversion(t *testing.T) {
+	now := gadget.WeatherNow{Temp: "77°F", WindSpeed: "5m/s", Vis: "800m", Pressure: "101.3kPa", Precip: "0.1in"}
+	if v, _ := now.TempC(); v != 25 {
+		t.Errorf("temp = %v", v)
+	}
+	if v, _ := now.WindSpeedKmh(); v != 18 {
+		t.Errorf("wind speed = %v", v)
+	}
+	if v, _ := now.VisKm(); v != 0.8 {
+		t.Errorf("visibility = %v", v)
+	}
+	if v, _ := now.PressureHpa(); v != 1013 {
+		t.Errorf("pressure = %v", v)
+	}
+	if v, _ := now.PrecipMm(); v < 2.539 || v > 2.541 {
+		t.Errorf("precip = %v", v)
+	}
+	if v, _ := (gadget.WeatherAQI{Co: "1200μg/m³"}).CoMgm3(); v != 1.2 {
+		t.Errorf("co = %v", v)
+	}
+	if _, err := gadget.ParseQuantity("12furlongs", gadget.KmPerHour); err == nil || errors.Is(err, gadget.ErrNoValue) {
+		t.Errorf("unknown unit should fail, got %v", err)
+	}
+}
+
+func TestLocationResolver(t *testing.T) {
+	r := gadget.DefaultLocations()
+	cases := []struct {
+		province, city, district, code string
+	}{
+		{"浙江省", "杭州市", "", "101210101"},
+		{"北京市", "北京市", "海淀区", "101010200"},
+		{"上海市", "市辖区", "", "101020100"},
+		{"上海", "上海", "浦东新区", "101021300"},
+		{"浙江省", "杭州市", "西湖区", "101210101"},
+		{"广西壮族自治区", "", "", "101300101"},
+		{"吉林省", "延边朝鲜族自治州", "", "101060301"},
+		{"云南省", "西双版纳傣族自治州", "", "101291601"},
+		{"", "深圳市", "", "101280601"},
+	}
+	for _, c := range cases {
+		loc, err := r.Resolve(c.province, c.city, c.district)
+		if err != nil || loc.Code != c.code {
+			t.Errorf("%s/%s/%s = %+v, %v", c.province, c.city, c.district, loc, err)
+		}
+	}
+	if _, err := r.Resolve("", "不存在", ""); !errors.Is(err, gadget.ErrLocationNotFound) {
+		t.Errorf("want not found, got %v", err)
+	}
+	// 城市找不到时不退回省会
+	if loc, err := r.Resolve("广西壮族自治区", "某市", ""); !errors.Is(err, gadget.ErrLocationNotFound) {
+		t.Errorf("want not found, got %+v, %v", loc, err)
+	}
+	if loc, _ := r.Resolve("浙江省", "杭州市", "西湖区"); loc.Level != division.LevelCity {
+		t.Errorf("district fallback level = %s", loc.Level)
+	}
+	if loc, _ := r.Resolve("北京市", "北京市", "海淀区"); loc.Level != division.LevelDistrict {
+		t.Errorf("district level = %s", loc.Level)
+	}
+	if loc, _ := r.Resolve("广东", "", ""); loc.Level != division.LevelProvince {
+		t.Errorf("province level = %s", loc.Level)
+	}
+
+	r = gadget.NewLocationResolver()
+	_ = r.Load(strings.NewReader("code,province,city,district\n1,辽宁,朝阳,\n2,北京,北京,朝阳\n3,北京,北京,\n"))
+	if _, err := r.Resolve("", "朝阳", ""); err != nil {
+		t.Errorf("district names must not match city lookups: %v", err)
+	}
+	err := r.LoadSids(strings.NewReader(`[{"sid":7,"province":"北京市","city":"北京市","district":"朝阳区"},{"sid":8,"code":"999"}]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	w, err := r.WeatherBySid(7, gadget.WithEnableForecast())
+	if err != nil || w.Code != "2" {
+		t.Errorf("sid 7 = %+v, %v", w, err)
+	}
+	if loc, _ := r.BySid(8); loc == nil || loc.Code != "999" {
+		t.Errorf("sid 8 = %+v", loc)
+	}
+	if _, err = r.BySid(9); !errors.Is(err, gadget.ErrLocationNotFound) {
+		t.Errorf("want not found, got %v", err)
+	}
+}
+
+// 天气位置数据与 geo 行政区划共用省市名称：内置位置的省市均在区划中，区划中的地级市均有位置代码
+func TestLocations_SharedDivisions(t *testing.T) {
+	f, err := os.Open("../odas/gadget/locations.csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	records, err := csv.NewReader(f).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tree := division.Default()
+	for _, r := range records[1:] {
+		if p, ok := tree.Province(r[1]); !ok || p.Name != r[1] {
+			t.Errorf("province %q not in divisions", r[1])
+		}
+		if c, _ := tree.City(r[1], r[2]); r[2] != "" && (c == nil || c.Name != r[2]) {
+			t.Errorf("city %q %q not in divisions", r[1], r[2])
+		}
+	}
+	locations := gadget.DefaultLocations()
+	for _, p := range tree.Provinces() {
+		for _, c := range p.Children {
+			if loc, err := locations.Resolve(p.Name, c.Name, ""); err != nil || loc.Level != division.LevelCity {
+				t.Errorf("%s %s = %+v, %v", p.Name, c.Name, loc, err)
+			}
+		}
+	}
+	if loc, err := locations.Resolve("", "延边", ""); err != nil || loc.Code != "101060301" {
+		t.Errorf("延边 = %+v, %v", loc, err)
+	}
+}